@@ -0,0 +1,117 @@
+package jntajis
+
+import "sort"
+
+// AlignmentSpan is a pair of corresponding ranges in the input and the output
+// of a conversion. Each span covers a single input character, which may be a
+// combining sequence, and everything emitted for it.
+type AlignmentSpan struct {
+	// byte range in the input
+	InStart, InEnd int
+	// rune range in the input
+	InRuneStart, InRuneEnd int
+	// byte range in the output
+	OutStart, OutEnd int
+	// range in the output in runes, or in JIS characters for encoded output
+	OutCharStart, OutCharEnd int
+}
+
+// Alignment maps offsets between the input and the output of a conversion.
+// Offsets that fall inside a span are mapped to the start of the
+// corresponding span.
+type Alignment struct {
+	spans []AlignmentSpan
+}
+
+func (a *Alignment) add(seg *segment, outStart, outEnd, outCharStart, outCharEnd int) {
+	a.spans = append(a.spans, AlignmentSpan{
+		InStart:      seg.offset,
+		InEnd:        seg.offset + seg.size,
+		InRuneStart:  seg.index,
+		InRuneEnd:    seg.index + seg.nrunes(),
+		OutStart:     outStart,
+		OutEnd:       outEnd,
+		OutCharStart: outCharStart,
+		OutCharEnd:   outCharEnd,
+	})
+}
+
+func (a *Alignment) Spans() []AlignmentSpan {
+	return a.spans
+}
+
+func (a *Alignment) find(o int, start func(sp *AlignmentSpan) int) int {
+	return sort.Search(len(a.spans), func(i int) bool {
+		return start(&a.spans[i]) > o
+	}) - 1
+}
+
+func (a *Alignment) project(o int, from func(sp *AlignmentSpan) int, fromEnd func(sp *AlignmentSpan) int, to func(sp *AlignmentSpan) int, toEnd func(sp *AlignmentSpan) int) int {
+	if len(a.spans) == 0 {
+		return 0
+	}
+	i := a.find(o, from)
+	if i < 0 {
+		return to(&a.spans[0])
+	}
+	sp := &a.spans[i]
+	if o >= fromEnd(sp) {
+		return toEnd(sp)
+	}
+	return to(sp)
+}
+
+func inStart(sp *AlignmentSpan) int      { return sp.InStart }
+func inEnd(sp *AlignmentSpan) int        { return sp.InEnd }
+func inRuneStart(sp *AlignmentSpan) int  { return sp.InRuneStart }
+func inRuneEnd(sp *AlignmentSpan) int    { return sp.InRuneEnd }
+func outStart(sp *AlignmentSpan) int     { return sp.OutStart }
+func outEnd(sp *AlignmentSpan) int       { return sp.OutEnd }
+func outCharStart(sp *AlignmentSpan) int { return sp.OutCharStart }
+func outCharEnd(sp *AlignmentSpan) int   { return sp.OutCharEnd }
+
+// InputOffset maps a byte offset in the output to that in the input.
+func (a *Alignment) InputOffset(o int) int {
+	return a.project(o, outStart, outEnd, inStart, inEnd)
+}
+
+// OutputOffset maps a byte offset in the input to that in the output.
+func (a *Alignment) OutputOffset(o int) int {
+	return a.project(o, inStart, inEnd, outStart, outEnd)
+}
+
+// InputRuneIndex maps a rune (or JIS character) index in the output to a rune
+// index in the input.
+func (a *Alignment) InputRuneIndex(i int) int {
+	return a.project(i, outCharStart, outCharEnd, inRuneStart, inRuneEnd)
+}
+
+// OutputRuneIndex maps a rune index in the input to a rune (or JIS character)
+// index in the output.
+func (a *Alignment) OutputRuneIndex(i int) int {
+	return a.project(i, inRuneStart, inRuneEnd, outCharStart, outCharEnd)
+}
+
+// InputRange maps a byte range in the output to the smallest byte range in
+// the input that covers it.
+func (a *Alignment) InputRange(start, end int) (int, int) {
+	return a.projectRange(start, end, outStart, outEnd, inStart, inEnd)
+}
+
+// OutputRange maps a byte range in the input to the smallest byte range in
+// the output that covers it.
+func (a *Alignment) OutputRange(start, end int) (int, int) {
+	return a.projectRange(start, end, inStart, inEnd, outStart, outEnd)
+}
+
+func (a *Alignment) projectRange(start, end int, from func(sp *AlignmentSpan) int, fromEnd func(sp *AlignmentSpan) int, to func(sp *AlignmentSpan) int, toEnd func(sp *AlignmentSpan) int) (int, int) {
+	s := a.project(start, from, fromEnd, to, toEnd)
+	if end <= start {
+		return s, s
+	}
+	i := a.find(end-1, from)
+	if i < 0 {
+		return s, s
+	}
+	return s, toEnd(&a.spans[i])
+}
@@ -0,0 +1,55 @@
+package jntajis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransliterateWithAlignment(t *testing.T) {
+	s, a := TransliterateWithAlignment("A﨑㉑か゚ジ")
+	assert.Equal(t, "A崎２１か゚ジ", s)
+	assert.Equal(t, []AlignmentSpan{
+		{InStart: 0, InEnd: 1, InRuneStart: 0, InRuneEnd: 1, OutStart: 0, OutEnd: 1, OutCharStart: 0, OutCharEnd: 1},
+		{InStart: 1, InEnd: 4, InRuneStart: 1, InRuneEnd: 2, OutStart: 1, OutEnd: 4, OutCharStart: 1, OutCharEnd: 2},
+		{InStart: 4, InEnd: 7, InRuneStart: 2, InRuneEnd: 3, OutStart: 4, OutEnd: 10, OutCharStart: 2, OutCharEnd: 4},
+		{InStart: 7, InEnd: 13, InRuneStart: 3, InRuneEnd: 5, OutStart: 10, OutEnd: 16, OutCharStart: 4, OutCharEnd: 6},
+		{InStart: 13, InEnd: 16, InRuneStart: 5, InRuneEnd: 6, OutStart: 16, OutEnd: 19, OutCharStart: 6, OutCharEnd: 7},
+	}, a.Spans())
+
+	// "１" in the middle of the transliterated form of "㉑"
+	assert.Equal(t, 4, a.InputOffset(7))
+	assert.Equal(t, 2, a.InputRuneIndex(3))
+	assert.Equal(t, 10, a.OutputOffset(7))
+	assert.Equal(t, 4, a.OutputRuneIndex(3))
+	// the combining mark of "か゚"
+	assert.Equal(t, 10, a.OutputOffset(10))
+	assert.Equal(t, len(s), a.OutputOffset(len("A﨑㉑か゚ジ")))
+	assert.Equal(t, len("A﨑㉑か゚ジ"), a.InputOffset(len(s)))
+
+	start, end := a.InputRange(7, 10)
+	assert.Equal(t, 4, start)
+	assert.Equal(t, 7, end)
+	start, end = a.OutputRange(1, 5)
+	assert.Equal(t, 1, start)
+	assert.Equal(t, 10, end)
+	start, end = a.OutputRange(3, 3)
+	assert.Equal(t, 1, start)
+	assert.Equal(t, 1, end)
+}
+
+func TestEncodeWithAlignment(t *testing.T) {
+	enc := NewJNTAJISEncoder(ConversionModeTranslit, InvalidJISCode)
+	b, a, err := enc.EncodeWithAlignment("ジ㉑か")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []byte{0x25, 0x38, 0x23, 0x32, 0x23, 0x31, 0x24, 0x2b}, b)
+	assert.Equal(t, []AlignmentSpan{
+		{InStart: 0, InEnd: 3, InRuneStart: 0, InRuneEnd: 1, OutStart: 0, OutEnd: 2, OutCharStart: 0, OutCharEnd: 1},
+		{InStart: 3, InEnd: 6, InRuneStart: 1, InRuneEnd: 2, OutStart: 2, OutEnd: 6, OutCharStart: 1, OutCharEnd: 3},
+		{InStart: 6, InEnd: 9, InRuneStart: 2, InRuneEnd: 3, OutStart: 6, OutEnd: 8, OutCharStart: 3, OutCharEnd: 4},
+	}, a.Spans())
+	assert.Equal(t, 3, a.InputOffset(4))
+	assert.Equal(t, 2, a.InputRuneIndex(3))
+}
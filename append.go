@@ -0,0 +1,27 @@
+package jntajis
+
+// AppendEncode appends the encoded form of s to dst, and returns dst as is on
+// error. It allocates nothing as long as dst has enough capacity.
+func (e *JNTAJISEncoder) AppendEncode(dst []byte, s string) ([]byte, error) {
+	r, err := e.encode(dst, s, nil)
+	if err != nil {
+		return dst, err
+	}
+	return r, nil
+}
+
+// AppendDecode is like Decode but returns dst as is on error. It allocates
+// nothing as long as dst has enough capacity.
+func (d *JNTAJISDecoder) AppendDecode(dst []byte, b []byte) ([]byte, error) {
+	r, err := d.Decode(dst, b)
+	if err != nil {
+		return dst, err
+	}
+	return r, nil
+}
+
+// AppendTransliterate appends the result of Transliterate(s) to dst. It
+// allocates nothing as long as dst has enough capacity.
+func AppendTransliterate(dst []byte, s string) []byte {
+	return appendTransliterated(dst, s, nil)
+}
@@ -0,0 +1,116 @@
+package jntajis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	benchKanjiText = "国税庁法人番号公表サイト高橋﨑山繫體龢"
+	benchKanaText  = "ジャンクロードヴァンダムかか゚ゔゕゖ"
+	benchASCIIText = "ABC Corporation 123 Ltd. "
+)
+
+func TestAppendEncode(t *testing.T) {
+	enc := NewJNTAJISEncoder(ConversionModeTranslit, MenKuTen{1, 2, 14}.JIS())
+	expected, err := enc.EncodeAsJISX0213Men1("亜繫\U00020089")
+	if !assert.NoError(t, err) {
+		return
+	}
+	b, err := enc.AppendEncode([]byte{0x21, 0x21}, "亜繫\U00020089")
+	if assert.NoError(t, err) {
+		assert.Equal(t, append([]byte{0x21, 0x21}, expected...), b)
+	}
+	b, err = NewJNTAJISEncoder(ConversionModeJISX0208, InvalidJISCode).AppendEncode([]byte("xx"), "亜\U00020089")
+	assert.Error(t, err)
+	assert.Equal(t, "xx", string(b))
+
+	dst := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		enc.AppendEncode(dst[:0], "亜繫\U00020089か゚")
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func TestAppendDecode(t *testing.T) {
+	dec := NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+	b, err := dec.AppendDecode([]byte("x"), []byte{0x30, 0x21, 0x0f, 0x21, 0x21, 0x0e})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "x亜\U00020089", string(b))
+	}
+	b, err = dec.AppendDecode([]byte("x"), []byte{0x30, 0x0a})
+	assert.Error(t, err)
+	assert.Equal(t, "x", string(b))
+
+	dst := make([]byte, 0, 64)
+	in := []byte{0x30, 0x21, 0x0f, 0x21, 0x21, 0x0e, 0x24, 0x77}
+	allocs := testing.AllocsPerRun(100, func() {
+		dec.AppendDecode(dst[:0], in)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func TestAppendTransliterate(t *testing.T) {
+	assert.Equal(t, "x"+Transliterate("繫ゔ"), string(AppendTransliterate([]byte("x"), "繫ゔ")))
+
+	dst := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		AppendTransliterate(dst[:0], "亜繫\U00020089か゚")
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func benchmarkAppendEncode(b *testing.B, s string) {
+	enc := NewJNTAJISEncoder(ConversionModeTranslit, MenKuTen{1, 2, 14}.JIS())
+	s = strings.Repeat(s, 100)
+	dst := make([]byte, 0, len(s)*2)
+	b.SetBytes(int64(len(s)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dst, _ = enc.AppendEncode(dst[:0], s)
+	}
+}
+
+func BenchmarkAppendEncodeKanji(b *testing.B) { benchmarkAppendEncode(b, benchKanjiText) }
+func BenchmarkAppendEncodeKana(b *testing.B)  { benchmarkAppendEncode(b, benchKanaText) }
+func BenchmarkAppendEncodeASCII(b *testing.B) { benchmarkAppendEncode(b, benchASCIIText) }
+
+func BenchmarkEncodeAsJISX0213Men1(b *testing.B) {
+	enc := NewJNTAJISEncoder(ConversionModeTranslit, MenKuTen{1, 2, 14}.JIS())
+	s := strings.Repeat(benchKanjiText, 100)
+	b.SetBytes(int64(len(s)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc.EncodeAsJISX0213Men1(s)
+	}
+}
+
+func BenchmarkAppendDecode(b *testing.B) {
+	enc := NewJNTAJISIncrementalEncoder(ConversionModeSISO, InvalidJISCode)
+	in, _ := enc.Encode(nil, strings.Repeat(benchKanjiText+benchKanaText, 100))
+	in, _ = enc.Flush(in)
+	dec := NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+	dst := make([]byte, 0, len(in)*2)
+	b.SetBytes(int64(len(in)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec.Reset()
+		dst, _ = dec.AppendDecode(dst[:0], in)
+	}
+}
+
+func BenchmarkAppendTransliterate(b *testing.B) {
+	s := strings.Repeat(benchKanjiText+benchKanaText, 100)
+	dst := make([]byte, 0, len(s)*2)
+	b.SetBytes(int64(len(s)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dst = AppendTransliterate(dst[:0], s)
+	}
+}
@@ -0,0 +1,87 @@
+package jntajis
+
+import "fmt"
+
+func (c JISCharacterClass) String() string {
+	switch c {
+	case Reserved:
+		return "Reserved"
+	case KanjiLevel1:
+		return "KanjiLevel1"
+	case KanjiLevel2:
+		return "KanjiLevel2"
+	case KanjiLevel3:
+		return "KanjiLevel3"
+	case KanjiLevel4:
+		return "KanjiLevel4"
+	case JISX0208NonKanji:
+		return "JISX0208NonKanji"
+	case JISX0213NonKanji:
+		return "JISX0213NonKanji"
+	default:
+		return fmt.Sprintf("??? (%d)", c)
+	}
+}
+
+// CharInfo describes a character of JIS X 0213 as found in the NTA table.
+type CharInfo struct {
+	// packed men-ku-ten code
+	JIS uint32
+	// corresponding Unicode character, which may be a combining sequence
+	Runes []rune
+	// secondary (similar glyph) Unicode character, if any
+	SimilarRunes []rune
+	Class        JISCharacterClass
+	// first edition of JIS X 0213 that contains the character
+	Edition JISX0213Edition
+	// transliterated form in packed men-ku-ten code
+	Translit []uint32
+	// transliterated form in Unicode
+	TranslitRunes []rune
+}
+
+func (ci *CharInfo) MenKuTen() MenKuTen {
+	return MenKuTenFromJIS(ci.JIS)
+}
+
+func runePairToSlice(p [2]rune) []rune {
+	if p[0] == InvalidRune {
+		return nil
+	} else if p[1] == InvalidRune {
+		return []rune{p[0]}
+	} else {
+		return []rune{p[0], p[1]}
+	}
+}
+
+// LookupCharInfo returns the information on the character at jis. It returns
+// false for reserved cells.
+func LookupCharInfo(jis uint32) (CharInfo, bool) {
+	if int(jis) >= len(txMappings()) {
+		return CharInfo{}, false
+	}
+	m := &txMappings()[jis]
+	if m.class == Reserved {
+		return CharInfo{}, false
+	}
+	ci := CharInfo{
+		JIS:           jis,
+		Runes:         runePairToSlice(m.rs),
+		SimilarRunes:  runePairToSlice(m.srs),
+		Class:         m.class,
+		Edition:       EditionOf(jis),
+		Translit:      append([]uint32(nil), m.txJIS[:m.txLen]...),
+		TranslitRunes: append([]rune(nil), m.txRunes[:m.txLen]...),
+	}
+	return ci, true
+}
+
+// LookupCharInfoByRune returns the information on the character that
+// corresponds to r. Combining sequences cannot be looked up by this function.
+func LookupCharInfoByRune(r rune) (CharInfo, bool) {
+	jis, ok := lookupRevTable(r)
+	if !ok {
+		return CharInfo{}, false
+	}
+	return LookupCharInfo(jis)
+}
@@ -0,0 +1,56 @@
+package jntajis
+
+// Summary is the result of Classify.
+type Summary struct {
+	// number of characters per class
+	Counts map[JISCharacterClass]int
+	// number of characters that are not in JIS X 0213
+	Unmapped int
+	// whether every character is in JIS X 0213
+	Representable bool
+	// the least capable of ConversionModeJISX0208, ConversionModeMen1 and
+	// ConversionModeSISO that represents the text without loss; meaningful
+	// only if Representable is set
+	MinimumMode ConversionMode
+	// the characters in JIS X 0213 but not in JIS X 0208, in order of
+	// appearance
+	NeedsTranslit []Problem
+}
+
+// Classify counts the characters of s per JIS character class and finds the
+// conversion mode that suits s.
+func Classify(s string) Summary {
+	sum := Summary{
+		Counts:        make(map[JISCharacterClass]int),
+		Representable: true,
+		MinimumMode:   ConversionModeJISX0208,
+	}
+	forEachSegment(s, func(seg segment) error {
+		jis, ok := segmentJIS(&seg)
+		if !ok {
+			sum.Unmapped++
+			sum.Representable = false
+			return nil
+		}
+		m := &txMappings()[jis]
+		sum.Counts[m.class]++
+		if isJISX0208(jis) {
+			return nil
+		}
+		if jis >= 94*94 {
+			sum.MinimumMode = ConversionModeSISO
+		} else if sum.MinimumMode == ConversionModeJISX0208 {
+			sum.MinimumMode = ConversionModeMen1
+		}
+		sum.NeedsTranslit = append(sum.NeedsTranslit, Problem{
+			Text:        s[seg.offset : seg.offset+seg.size],
+			Offset:      seg.offset,
+			Index:       seg.index,
+			JIS:         jis,
+			Class:       m.class,
+			HasTranslit: m.txLen > 0,
+		})
+		return nil
+	})
+	return sum
+}
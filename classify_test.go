@@ -0,0 +1,62 @@
+package jntajis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		expected Summary
+		input    string
+	}{
+		{
+			expected: Summary{
+				Counts:        map[JISCharacterClass]int{},
+				Representable: true,
+				MinimumMode:   ConversionModeJISX0208,
+			},
+			input: "",
+		},
+		{
+			expected: Summary{
+				Counts:        map[JISCharacterClass]int{KanjiLevel1: 2, JISX0208NonKanji: 3},
+				Representable: true,
+				MinimumMode:   ConversionModeJISX0208,
+			},
+			input: "山崎ジャン",
+		},
+		{
+			expected: Summary{
+				Counts:        map[JISCharacterClass]int{KanjiLevel1: 1, KanjiLevel3: 1, JISX0213NonKanji: 1},
+				Representable: true,
+				MinimumMode:   ConversionModeMen1,
+				NeedsTranslit: []Problem{
+					{Text: "﨑", Offset: 3, Index: 1, JIS: MenKuTen{1, 47, 82}.JIS(), Class: KanjiLevel3, HasTranslit: true},
+					{Text: "か゚", Offset: 6, Index: 2, JIS: MenKuTen{1, 4, 87}.JIS(), Class: JISX0213NonKanji},
+				},
+			},
+			input: "山﨑か゚",
+		},
+		{
+			expected: Summary{
+				Counts:        map[JISCharacterClass]int{KanjiLevel1: 1, KanjiLevel4: 1},
+				Unmapped:      1,
+				Representable: false,
+				MinimumMode:   ConversionModeSISO,
+				NeedsTranslit: []Problem{
+					{Text: "\U00020089", Offset: 3, Index: 1, JIS: MenKuTen{2, 1, 1}.JIS(), Class: KanjiLevel4},
+				},
+			},
+			input: "山\U00020089A",
+		},
+	}
+
+	for i, case_ := range cases {
+		t.Run(fmt.Sprintf("%d: %s", i, case_.input), func(t *testing.T) {
+			assert.Equal(t, case_.expected, Classify(case_.input))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package jntajis
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// UnrepresentablePolicy tells a Collator where to put the characters that are
+// not in JIS X 0213.
+type UnrepresentablePolicy int
+
+const (
+	// before every JIS character, in the order of Unicode code points
+	UnrepresentableFirst = UnrepresentablePolicy(iota)
+	// after every JIS character, in the order of Unicode code points
+	UnrepresentableLast
+	// fail with an error
+	UnrepresentableError
+)
+
+// Collator orders strings by the sequence of JIS X 0213 men-ku-ten codes of
+// their characters.
+type Collator struct {
+	Unrepresentable UnrepresentablePolicy
+	// Shrink makes the characters outside JIS X 0208 compare as their
+	// transliterated forms.
+	Shrink bool
+}
+
+const (
+	collationTagFirst = byte(iota)
+	collationTagJIS
+	collationTagLast
+)
+
+func (c *Collator) appendKey(b []byte, s string) ([]byte, error) {
+	err := forEachSegment(s, func(seg segment) error {
+		jis, ok := segmentJIS(&seg)
+		if !ok {
+			var tag byte
+			switch c.Unrepresentable {
+			case UnrepresentableFirst:
+				tag = collationTagFirst
+			case UnrepresentableLast:
+				tag = collationTagLast
+			default:
+				return fmt.Errorf("%s is not convertible to JISX0213", s[seg.offset:seg.offset+seg.size])
+			}
+			for _, r := range s[seg.offset : seg.offset+seg.size] {
+				b = append(b, tag, byte(r>>16), byte(r>>8), byte(r))
+			}
+			return nil
+		}
+		if c.Shrink && !isJISX0208(jis) {
+			if m := &txMappings()[jis]; m.txLen > 0 {
+				for _, c := range m.txJIS[:m.txLen] {
+					b = append(b, collationTagJIS, byte(c>>8), byte(c))
+				}
+				return nil
+			}
+		}
+		b = append(b, collationTagJIS, byte(jis>>8), byte(jis))
+		return nil
+	})
+	return b, err
+}
+
+// SortKey returns a key of s such that comparing the keys of two strings with
+// bytes.Compare gives the same result as Compare.
+func (c *Collator) SortKey(s string) ([]byte, error) {
+	return c.appendKey(make([]byte, 0, len(s)*3), s)
+}
+
+// Compare returns an integer comparing a and b in the JIS code order.
+func (c *Collator) Compare(a, b string) (int, error) {
+	ka, err := c.SortKey(a)
+	if err != nil {
+		return 0, err
+	}
+	kb, err := c.SortKey(b)
+	if err != nil {
+		return 0, err
+	}
+	return bytes.Compare(ka, kb), nil
+}
@@ -0,0 +1,71 @@
+package jntajis
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sortByCollator(t *testing.T, c *Collator, ss []string) []string {
+	keys := make(map[string][]byte, len(ss))
+	for _, s := range ss {
+		k, err := c.SortKey(s)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		keys[s] = k
+	}
+	retval := append([]string(nil), ss...)
+	sort.SliceStable(retval, func(i, j int) bool {
+		return bytes.Compare(keys[retval[i]], keys[retval[j]]) < 0
+	})
+	return retval
+}
+
+func TestCollator(t *testing.T) {
+	input := []string{"亜", "ア", "あ", "ああ", "A", "﨑", "崎", "\U00020089", "", "Z亜"}
+	cases := []struct {
+		expected []string
+		collator Collator
+	}{
+		{
+			expected: []string{"", "A", "Z亜", "あ", "ああ", "ア", "亜", "崎", "﨑", "\U00020089"},
+			collator: Collator{Unrepresentable: UnrepresentableFirst},
+		},
+		{
+			expected: []string{"", "あ", "ああ", "ア", "亜", "崎", "﨑", "\U00020089", "A", "Z亜"},
+			collator: Collator{Unrepresentable: UnrepresentableLast},
+		},
+		{
+			expected: []string{"", "あ", "ああ", "ア", "亜", "﨑", "崎", "\U00020089", "A", "Z亜"},
+			collator: Collator{Unrepresentable: UnrepresentableLast, Shrink: true},
+		},
+	}
+
+	for i, case_ := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert.Equal(t, case_.expected, sortByCollator(t, &case_.collator, input))
+		})
+	}
+
+	c := Collator{Shrink: true}
+	r, err := c.Compare("山﨑", "山崎")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, r)
+	}
+	c = Collator{}
+	r, err = c.Compare("山﨑", "山崎")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, r)
+	}
+	r, err = c.Compare("ア", "亜")
+	if assert.NoError(t, err) {
+		assert.Equal(t, -1, r)
+	}
+	c = Collator{Unrepresentable: UnrepresentableError}
+	_, err = c.Compare("亜", "A")
+	assert.EqualError(t, err, "A is not convertible to JISX0213")
+}
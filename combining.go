@@ -0,0 +1,322 @@
+package jntajis
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// CombiningForm selects how the decoder emits the characters that consist of
+// a base character and combining marks.
+type CombiningForm int
+
+const (
+	// as given in the NTA table
+	CombiningFormAsIs = CombiningForm(iota)
+	// composed into a single code point where Unicode has one, with the
+	// singletons such as U+212B ANGSTROM SIGN replaced as NFC does. Unlike
+	// NFC, the CJK compatibility ideographs such as U+FA30 are kept, since
+	// they tell apart the glyphs of names.
+	CombiningFormNFC
+	// decomposed into the base character and the combining marks. The CJK
+	// compatibility ideographs are kept as in CombiningFormNFC.
+	CombiningFormNFD
+	// the base character alone, for consumers that cannot render
+	// combining marks
+	CombiningFormBase
+	// ReservedPUABase+c for the cell c, for consumers that cannot render
+	// combining marks. The encoders do not map these code points back, as
+	// ReservedAsPUA only covers the reserved cells.
+	CombiningFormPUA
+)
+
+// canonicalDecompositions holds the canonical decompositions of the
+// precomposed characters in JIS X 0213 (Unicode 14.0.0).
+var canonicalDecompositions = map[rune]string{
+	0x00c0: "\u0041\u0300",       // À
+	0x00c1: "\u0041\u0301",       // Á
+	0x00c2: "\u0041\u0302",       // Â
+	0x00c3: "\u0041\u0303",       // Ã
+	0x00c4: "\u0041\u0308",       // Ä
+	0x00c5: "\u0041\u030a",       // Å
+	0x00c7: "\u0043\u0327",       // Ç
+	0x00c8: "\u0045\u0300",       // È
+	0x00c9: "\u0045\u0301",       // É
+	0x00ca: "\u0045\u0302",       // Ê
+	0x00cb: "\u0045\u0308",       // Ë
+	0x00cc: "\u0049\u0300",       // Ì
+	0x00cd: "\u0049\u0301",       // Í
+	0x00ce: "\u0049\u0302",       // Î
+	0x00cf: "\u0049\u0308",       // Ï
+	0x00d1: "\u004e\u0303",       // Ñ
+	0x00d2: "\u004f\u0300",       // Ò
+	0x00d3: "\u004f\u0301",       // Ó
+	0x00d4: "\u004f\u0302",       // Ô
+	0x00d5: "\u004f\u0303",       // Õ
+	0x00d6: "\u004f\u0308",       // Ö
+	0x00d9: "\u0055\u0300",       // Ù
+	0x00da: "\u0055\u0301",       // Ú
+	0x00db: "\u0055\u0302",       // Û
+	0x00dc: "\u0055\u0308",       // Ü
+	0x00dd: "\u0059\u0301",       // Ý
+	0x00e0: "\u0061\u0300",       // à
+	0x00e1: "\u0061\u0301",       // á
+	0x00e2: "\u0061\u0302",       // â
+	0x00e3: "\u0061\u0303",       // ã
+	0x00e4: "\u0061\u0308",       // ä
+	0x00e5: "\u0061\u030a",       // å
+	0x00e7: "\u0063\u0327",       // ç
+	0x00e8: "\u0065\u0300",       // è
+	0x00e9: "\u0065\u0301",       // é
+	0x00ea: "\u0065\u0302",       // ê
+	0x00eb: "\u0065\u0308",       // ë
+	0x00ec: "\u0069\u0300",       // ì
+	0x00ed: "\u0069\u0301",       // í
+	0x00ee: "\u0069\u0302",       // î
+	0x00ef: "\u0069\u0308",       // ï
+	0x00f1: "\u006e\u0303",       // ñ
+	0x00f2: "\u006f\u0300",       // ò
+	0x00f3: "\u006f\u0301",       // ó
+	0x00f4: "\u006f\u0302",       // ô
+	0x00f5: "\u006f\u0303",       // õ
+	0x00f6: "\u006f\u0308",       // ö
+	0x00f9: "\u0075\u0300",       // ù
+	0x00fa: "\u0075\u0301",       // ú
+	0x00fb: "\u0075\u0302",       // û
+	0x00fc: "\u0075\u0308",       // ü
+	0x00fd: "\u0079\u0301",       // ý
+	0x00ff: "\u0079\u0308",       // ÿ
+	0x0100: "\u0041\u0304",       // Ā
+	0x0101: "\u0061\u0304",       // ā
+	0x0102: "\u0041\u0306",       // Ă
+	0x0103: "\u0061\u0306",       // ă
+	0x0104: "\u0041\u0328",       // Ą
+	0x0105: "\u0061\u0328",       // ą
+	0x0106: "\u0043\u0301",       // Ć
+	0x0107: "\u0063\u0301",       // ć
+	0x0108: "\u0043\u0302",       // Ĉ
+	0x0109: "\u0063\u0302",       // ĉ
+	0x010c: "\u0043\u030c",       // Č
+	0x010d: "\u0063\u030c",       // č
+	0x010e: "\u0044\u030c",       // Ď
+	0x010f: "\u0064\u030c",       // ď
+	0x0112: "\u0045\u0304",       // Ē
+	0x0113: "\u0065\u0304",       // ē
+	0x0118: "\u0045\u0328",       // Ę
+	0x0119: "\u0065\u0328",       // ę
+	0x011a: "\u0045\u030c",       // Ě
+	0x011b: "\u0065\u030c",       // ě
+	0x011c: "\u0047\u0302",       // Ĝ
+	0x011d: "\u0067\u0302",       // ĝ
+	0x0124: "\u0048\u0302",       // Ĥ
+	0x0125: "\u0068\u0302",       // ĥ
+	0x012a: "\u0049\u0304",       // Ī
+	0x012b: "\u0069\u0304",       // ī
+	0x0134: "\u004a\u0302",       // Ĵ
+	0x0135: "\u006a\u0302",       // ĵ
+	0x0139: "\u004c\u0301",       // Ĺ
+	0x013a: "\u006c\u0301",       // ĺ
+	0x013d: "\u004c\u030c",       // Ľ
+	0x013e: "\u006c\u030c",       // ľ
+	0x0143: "\u004e\u0301",       // Ń
+	0x0144: "\u006e\u0301",       // ń
+	0x0147: "\u004e\u030c",       // Ň
+	0x0148: "\u006e\u030c",       // ň
+	0x014c: "\u004f\u0304",       // Ō
+	0x014d: "\u006f\u0304",       // ō
+	0x0150: "\u004f\u030b",       // Ő
+	0x0151: "\u006f\u030b",       // ő
+	0x0154: "\u0052\u0301",       // Ŕ
+	0x0155: "\u0072\u0301",       // ŕ
+	0x0158: "\u0052\u030c",       // Ř
+	0x0159: "\u0072\u030c",       // ř
+	0x015a: "\u0053\u0301",       // Ś
+	0x015b: "\u0073\u0301",       // ś
+	0x015c: "\u0053\u0302",       // Ŝ
+	0x015d: "\u0073\u0302",       // ŝ
+	0x015e: "\u0053\u0327",       // Ş
+	0x015f: "\u0073\u0327",       // ş
+	0x0160: "\u0053\u030c",       // Š
+	0x0161: "\u0073\u030c",       // š
+	0x0162: "\u0054\u0327",       // Ţ
+	0x0163: "\u0074\u0327",       // ţ
+	0x0164: "\u0054\u030c",       // Ť
+	0x0165: "\u0074\u030c",       // ť
+	0x016a: "\u0055\u0304",       // Ū
+	0x016b: "\u0075\u0304",       // ū
+	0x016c: "\u0055\u0306",       // Ŭ
+	0x016d: "\u0075\u0306",       // ŭ
+	0x016e: "\u0055\u030a",       // Ů
+	0x016f: "\u0075\u030a",       // ů
+	0x0170: "\u0055\u030b",       // Ű
+	0x0171: "\u0075\u030b",       // ű
+	0x0179: "\u005a\u0301",       // Ź
+	0x017a: "\u007a\u0301",       // ź
+	0x017b: "\u005a\u0307",       // Ż
+	0x017c: "\u007a\u0307",       // ż
+	0x017d: "\u005a\u030c",       // Ž
+	0x017e: "\u007a\u030c",       // ž
+	0x01cd: "\u0041\u030c",       // Ǎ
+	0x01ce: "\u0061\u030c",       // ǎ
+	0x01d0: "\u0069\u030c",       // ǐ
+	0x01d1: "\u004f\u030c",       // Ǒ
+	0x01d2: "\u006f\u030c",       // ǒ
+	0x01d4: "\u0075\u030c",       // ǔ
+	0x01d6: "\u0075\u0308\u0304", // ǖ
+	0x01d8: "\u0075\u0308\u0301", // ǘ
+	0x01da: "\u0075\u0308\u030c", // ǚ
+	0x01dc: "\u0075\u0308\u0300", // ǜ
+	0x01f8: "\u004e\u0300",       // Ǹ
+	0x01f9: "\u006e\u0300",       // ǹ
+	0x01fd: "\u00e6\u0301",       // ǽ
+	0x0401: "\u0415\u0308",       // Ё
+	0x0419: "\u0418\u0306",       // Й
+	0x0439: "\u0438\u0306",       // й
+	0x0451: "\u0435\u0308",       // ё
+	0x1e3e: "\u004d\u0301",       // Ḿ
+	0x1e3f: "\u006d\u0301",       // ḿ
+	0x1f70: "\u03b1\u0300",       // ὰ
+	0x1f71: "\u03b1\u0301",       // ά
+	0x1f72: "\u03b5\u0300",       // ὲ
+	0x1f73: "\u03b5\u0301",       // έ
+	0x212b: "\u0041\u030a",       // Å
+	0x2209: "\u2208\u0338",       // ∉
+	0x2226: "\u2225\u0338",       // ∦
+	0x2260: "\u003d\u0338",       // ≠
+	0x2262: "\u2261\u0338",       // ≢
+	0x2284: "\u2282\u0338",       // ⊄
+	0x2285: "\u2283\u0338",       // ⊅
+	0x304c: "\u304b\u3099",       // が
+	0x304e: "\u304d\u3099",       // ぎ
+	0x3050: "\u304f\u3099",       // ぐ
+	0x3052: "\u3051\u3099",       // げ
+	0x3054: "\u3053\u3099",       // ご
+	0x3056: "\u3055\u3099",       // ざ
+	0x3058: "\u3057\u3099",       // じ
+	0x305a: "\u3059\u3099",       // ず
+	0x305c: "\u305b\u3099",       // ぜ
+	0x305e: "\u305d\u3099",       // ぞ
+	0x3060: "\u305f\u3099",       // だ
+	0x3062: "\u3061\u3099",       // ぢ
+	0x3065: "\u3064\u3099",       // づ
+	0x3067: "\u3066\u3099",       // で
+	0x3069: "\u3068\u3099",       // ど
+	0x3070: "\u306f\u3099",       // ば
+	0x3071: "\u306f\u309a",       // ぱ
+	0x3073: "\u3072\u3099",       // び
+	0x3074: "\u3072\u309a",       // ぴ
+	0x3076: "\u3075\u3099",       // ぶ
+	0x3077: "\u3075\u309a",       // ぷ
+	0x3079: "\u3078\u3099",       // べ
+	0x307a: "\u3078\u309a",       // ぺ
+	0x307c: "\u307b\u3099",       // ぼ
+	0x307d: "\u307b\u309a",       // ぽ
+	0x3094: "\u3046\u3099",       // ゔ
+	0x309e: "\u309d\u3099",       // ゞ
+	0x30ac: "\u30ab\u3099",       // ガ
+	0x30ae: "\u30ad\u3099",       // ギ
+	0x30b0: "\u30af\u3099",       // グ
+	0x30b2: "\u30b1\u3099",       // ゲ
+	0x30b4: "\u30b3\u3099",       // ゴ
+	0x30b6: "\u30b5\u3099",       // ザ
+	0x30b8: "\u30b7\u3099",       // ジ
+	0x30ba: "\u30b9\u3099",       // ズ
+	0x30bc: "\u30bb\u3099",       // ゼ
+	0x30be: "\u30bd\u3099",       // ゾ
+	0x30c0: "\u30bf\u3099",       // ダ
+	0x30c2: "\u30c1\u3099",       // ヂ
+	0x30c5: "\u30c4\u3099",       // ヅ
+	0x30c7: "\u30c6\u3099",       // デ
+	0x30c9: "\u30c8\u3099",       // ド
+	0x30d0: "\u30cf\u3099",       // バ
+	0x30d1: "\u30cf\u309a",       // パ
+	0x30d3: "\u30d2\u3099",       // ビ
+	0x30d4: "\u30d2\u309a",       // ピ
+	0x30d6: "\u30d5\u3099",       // ブ
+	0x30d7: "\u30d5\u309a",       // プ
+	0x30d9: "\u30d8\u3099",       // ベ
+	0x30da: "\u30d8\u309a",       // ペ
+	0x30dc: "\u30db\u3099",       // ボ
+	0x30dd: "\u30db\u309a",       // ポ
+	0x30f4: "\u30a6\u3099",       // ヴ
+	0x30f7: "\u30ef\u3099",       // ヷ
+	0x30f8: "\u30f0\u3099",       // ヸ
+	0x30f9: "\u30f1\u3099",       // ヹ
+	0x30fa: "\u30f2\u3099",       // ヺ
+	0x30fe: "\u30fd\u3099",       // ヾ
+}
+
+// canonicalSingletons maps the characters in canonicalDecompositions that
+// NFC turns into another character to that character. The CJK compatibility
+// ideographs are left out on purpose; see CombiningFormNFC.
+var canonicalSingletons = map[rune]rune{
+	0x1f71: 0x03ac, // ά
+	0x1f73: 0x03ad, // έ
+	0x212b: 0x00c5, // Å
+}
+
+var canonicalCompositions = func() map[[2]rune]rune {
+	c := make(map[[2]rune]rune)
+	// compose the shorter decompositions first so that the longer ones can
+	// be built on top of them
+	for n := 2; n <= 3; n++ {
+		for r, d := range canonicalDecompositions {
+			if utf8.RuneCountInString(d) != n {
+				continue
+			}
+			if _, ok := canonicalSingletons[r]; ok {
+				continue
+			}
+			rs := []rune(d)
+			base := rs[0]
+			if n == 3 {
+				var ok bool
+				base, ok = c[[2]rune{rs[0], rs[1]}]
+				if !ok {
+					continue
+				}
+			}
+			c[[2]rune{base, rs[n-1]}] = r
+		}
+	}
+	return c
+}()
+
+func appendDecomposed(b []byte, r rune) []byte {
+	if d, ok := canonicalDecompositions[r]; ok {
+		return append(b, d...)
+	}
+	return appendRune(b, r)
+}
+
+// appendCombiningForm appends the characters rs of the cell jis in the form
+// of d.Form, and returns false if the form does not apply.
+func (d *JNTAJISDecoder) appendCombiningForm(b []byte, jis uint32, rs *[2]rune) ([]byte, bool) {
+	switch d.Form {
+	case CombiningFormNFC:
+		if rs[1] == InvalidRune {
+			if r, ok := canonicalSingletons[rs[0]]; ok {
+				return appendRune(b, r), true
+			}
+		} else if r, ok := canonicalCompositions[*rs]; ok {
+			return appendRune(b, r), true
+		}
+	case CombiningFormNFD:
+		if _, ok := canonicalDecompositions[rs[0]]; ok {
+			b = grow(b, len(b)+12)
+			b = appendDecomposed(b, rs[0])
+			if rs[1] != InvalidRune {
+				b = appendDecomposed(b, rs[1])
+			}
+			return b, true
+		}
+	case CombiningFormBase:
+		if rs[1] != InvalidRune && unicode.Is(unicode.Mn, rs[1]) {
+			return appendRune(b, rs[0]), true
+		}
+	case CombiningFormPUA:
+		if rs[1] != InvalidRune && unicode.Is(unicode.Mn, rs[1]) {
+			return appendRune(b, reservedPUA(jis)), true
+		}
+	}
+	return b, false
+}
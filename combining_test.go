@@ -0,0 +1,57 @@
+package jntajis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCombiningForm(t *testing.T) {
+	// 1-14-24 is U+FA30, which NFC and NFD would turn into U+4FAE
+	in := []byte{0x24, 0x2c, 0x24, 0x77, 0x30, 0x21, 0x22, 0x72, 0x2b, 0x47, 0x2e, 0x38}
+	for _, c := range []struct {
+		form     CombiningForm
+		expected string
+	}{
+		{CombiningFormAsIs, "がか゚亜\u212b\u1f71\ufa30"},
+		{CombiningFormNFC, "がか゚亜\u00c5\u03ac\ufa30"},
+		{CombiningFormNFD, "か\u3099か゚亜A\u030a\u03b1\u0301\ufa30"},
+		{CombiningFormBase, "がか亜\u212b\u1f71\ufa30"},
+		{CombiningFormPUA, "が\U000f0170亜\u212b\u1f71\ufa30"},
+	} {
+		dec := NewJNTAJISDecoder(ConversionModeMen1, InvalidRune)
+		dec.Form = c.form
+		b, err := dec.Decode(nil, in)
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.expected, string(b), c.form)
+		}
+	}
+
+	// the PUA form of a non-reserved cell does not encode back
+	enc := NewJNTAJISEncoder(ConversionModeMen1, InvalidJISCode)
+	enc.ReservedAsPUA = true
+	_, err := enc.EncodeAsJISX0213Men1("\U000f0170")
+	assert.Error(t, err)
+}
+
+func TestCanonicalCompositions(t *testing.T) {
+	assert.Equal(t, len(canonicalDecompositions)-len(canonicalSingletons), len(canonicalCompositions))
+	for r, d := range canonicalDecompositions {
+		if s, ok := canonicalSingletons[r]; ok {
+			if sd, ok := canonicalDecompositions[s]; ok {
+				assert.Equal(t, sd, d, "%U", r)
+			}
+			continue
+		}
+		rs := []rune(d)
+		c := rs[0]
+		for _, m := range rs[1:] {
+			var ok bool
+			c, ok = canonicalCompositions[[2]rune{c, m}]
+			if !assert.True(t, ok, "%U", r) {
+				break
+			}
+		}
+		assert.Equal(t, r, c)
+	}
+}
@@ -5,13 +5,53 @@ import (
 	"unicode/utf8"
 )
 
+// JNTAJISDecoder keeps the state across calls to Decode, so it must not be
+// shared between goroutines. See Decode for a safe alternative.
 type JNTAJISDecoder struct {
 	Replacement rune
+	Gaiji       *GaijiRegistry
+	// ReservedAsPUA makes reserved cells decode to ReservedPUABase+c instead
+	// of the replacement character.
+	ReservedAsPUA bool
+	// Edition makes the characters that are not in the edition decode to
+	// the replacement character.
+	Edition JISX0213Edition
+	// ControlPassthrough makes the C0 control characters and DEL decode to
+	// themselves, except SO and SI in SISO mode.
+	ControlPassthrough bool
+	// Translit makes the characters outside JIS X 0208 decode to their
+	// transliterated form in the NTA table where there is one.
+	Translit bool
+	// Preference selects the Unicode form of the characters that have a
+	// similar glyph form in the NTA table.
+	Preference RunePreference
+	// Preferences maps the primary form of a character to the code point to
+	// decode to, taking precedence over Preference.
+	Preferences map[rune]rune
+	// Form selects how the characters with combining marks are emitted.
+	Form        CombiningForm
 	siso        bool
 	shiftOffset int
 	upper       int
 }
 
+// RunePreference selects either of the Unicode forms given in the NTA table.
+type RunePreference int
+
+const (
+	// the primary form
+	PreferPrimary = RunePreference(iota)
+	// the similar glyph (類似字形) form where there is one
+	PreferSimilarGlyph
+)
+
+func isControlByte(c int, siso bool) bool {
+	if siso && (c == 0x0e || c == 0x0f) {
+		return false
+	}
+	return c < 0x20 || c == 0x7f
+}
+
 func grow(b []byte, req int) []byte {
 	v := cap(b)
 	if v >= req {
@@ -47,58 +87,139 @@ func (d *JNTAJISDecoder) appendReplacement(b []byte, o int) ([]byte, error) {
 	}
 }
 
-func (d *JNTAJISDecoder) Decode(b []byte, in_ []byte) ([]byte, error) {
-	var err error
-	i := 0
-	for i < len(in_) {
-		var c0 int
-		if d.upper > 0 {
-			c0 = d.upper
-			d.upper = 0
+func appendRune(b []byte, r rune) []byte {
+	b = grow(b, len(b)+4)
+	n := utf8.EncodeRune(b[len(b):len(b)+4], r)
+	return b[:len(b)+n]
+}
+
+func (d *JNTAJISDecoder) appendJIS(b []byte, jis uint32, o int) ([]byte, error) {
+	m := &txMappings()[jis]
+	if m.class == Reserved {
+		if d.Gaiji != nil {
+			if ge, ok := d.Gaiji.byJIS[jis]; ok {
+				return appendRune(b, ge.r), nil
+			}
+		}
+		if d.ReservedAsPUA {
+			return appendRune(b, reservedPUA(jis)), nil
+		}
+		return d.appendReplacement(b, o)
+	}
+	if d.Translit && m.txLen > 0 && !isJISX0208(jis) {
+		return appendRunes(b, m.txRunes[:m.txLen]), nil
+	}
+	if isUnavailableInEdition(jis, d.Edition) {
+		return d.appendReplacement(b, o)
+	}
+	rs := &m.rs
+	if rs[1] == InvalidRune && d.Preferences != nil {
+		if r, ok := d.Preferences[rs[0]]; ok {
+			return appendRune(b, r), nil
+		}
+	}
+	rs = d.preferredRunes(m)
+	if d.Form != CombiningFormAsIs {
+		var ok bool
+		if b, ok = d.appendCombiningForm(b, jis, rs); ok {
+			return b, nil
+		}
+	}
+	if rs[1] == InvalidRune {
+		b = grow(b, len(b)+4)
+		n := utf8.EncodeRune(b[len(b):len(b)+4], rs[0])
+		b = b[:len(b)+n]
+	} else {
+		b = grow(b, len(b)+8)
+		n := utf8.EncodeRune(b[len(b):len(b)+4], rs[0])
+		n += utf8.EncodeRune(b[len(b)+n:len(b)+n+4], rs[1])
+		b = b[:len(b)+n]
+	}
+	return b, nil
+}
+
+// preferredRunes returns the Unicode form of m selected by Preference.
+func (d *JNTAJISDecoder) preferredRunes(m *shrinkingTransliterationMapping) *[2]rune {
+	if d.Preference == PreferSimilarGlyph && m.srs[0] != InvalidRune {
+		return &m.srs
+	}
+	return &m.rs
+}
+
+const (
+	// a shift byte, or the upper half of a character pending at the end
+	unitNone = iota
+	unitJIS
+	unitControl
+)
+
+// nextUnit reads a unit from in_ at i, and returns its kind, the packed
+// men-ku-ten code or the control byte, its offset and the position next to it.
+func (d *JNTAJISDecoder) nextUnit(in_ []byte, i int) (int, int, int, int, error) {
+	var c0 int
+	if d.upper > 0 {
+		c0 = d.upper
+		d.upper = 0
+	} else {
+		c0 = int(in_[i])
+		i += 1
+	}
+	if c0 >= 0x21 && c0 <= 0x7e {
+		if i >= len(in_) {
+			d.upper = c0
+			return unitNone, 0, i - 1, i, nil
+		}
+		c1 := int(in_[i])
+		i += 1
+		if c1 >= 0x21 && c1 <= 0x7e {
+			return unitJIS, d.shiftOffset + (c0-0x21)*94 + (c1 - 0x21), i - 2, i, nil
 		} else {
-			c0 = int(in_[i])
-			i += 1
+			return unitNone, 0, i - 2, i, fmt.Errorf("unexpected byte \\x%02x after \\x%02x at offset %d", c1, c0, i-2)
 		}
-		if c0 >= 0x21 && c0 <= 0x7e {
-			if i >= len(in_) {
-				d.upper = c0
-				return b, nil
-			}
-			c1 := int(in_[i])
-			i += 1
-			if c1 >= 0x21 && c1 <= 0x7e {
-				jis := d.shiftOffset + (c0-0x21)*94 + (c1 - 0x21)
-				m := &txMappings[jis]
-				if m.class == Reserved {
-					b, err = d.appendReplacement(b, i-2)
-					if err != nil {
-						return b, err
-					}
-				} else {
-					if m.rs[1] == InvalidRune {
-						b = grow(b, len(b)+4)
-						n := utf8.EncodeRune(b[len(b):len(b)+4], m.rs[0])
-						b = b[:len(b)+n]
-					} else {
-						b = grow(b, len(b)+8)
-						n := utf8.EncodeRune(b[len(b):len(b)+4], m.rs[0])
-						n += utf8.EncodeRune(b[len(b)+n:len(b)+n+4], m.rs[1])
-						b = b[:len(b)+n]
-					}
-				}
-			} else {
-				return nil, fmt.Errorf("unexpected byte \\x%02x after \\x%02x at offset %d", c1, c0, i-2)
-			}
+	} else {
+		siso := d.siso
+		if c0 == 0x0e && siso {
+			d.shiftOffset = 0
+		} else if c0 == 0x0f && siso {
+			d.shiftOffset = 94 * 94
+		} else if d.ControlPassthrough && isControlByte(c0, siso) {
+			return unitControl, c0, i - 1, i, nil
 		} else {
-			siso := d.siso
-			if c0 == 0x0e && siso {
-				d.shiftOffset = 0
-			} else if c0 == 0x0f && siso {
-				d.shiftOffset = 94 * 94
-			} else {
-				return nil, fmt.Errorf("unexpected byte \\x%02x at offset %d", c0, i)
+			return unitNone, 0, i - 1, i, fmt.Errorf("unexpected byte \\x%02x at offset %d", c0, i-1)
+		}
+		return unitNone, 0, i - 1, i, nil
+	}
+}
+
+func (d *JNTAJISDecoder) Decode(b []byte, in_ []byte) ([]byte, error) {
+	return d.decode(b, in_, 0)
+}
+
+// decode decodes in_ from i on, so that the offsets in the errors are those
+// in in_.
+func (d *JNTAJISDecoder) decode(b []byte, in_ []byte, i int) ([]byte, error) {
+	for i < len(in_) {
+		kind, v, o, ni, err := d.nextUnit(in_, i)
+		if err != nil {
+			return nil, err
+		}
+		i = ni
+		switch kind {
+		case unitJIS:
+			b, err = d.appendJIS(b, uint32(v), o)
+			if err != nil {
+				return b, err
 			}
+		case unitControl:
+			b = appendRune(b, rune(v))
 		}
 	}
 	return b, nil
 }
+
+func NewJNTAJISDecoder(mode ConversionMode, replacement rune) *JNTAJISDecoder {
+	return &JNTAJISDecoder{
+		Replacement: replacement,
+		siso:        mode == ConversionModeSISO,
+	}
+}
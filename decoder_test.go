@@ -9,7 +9,7 @@ import (
 )
 
 func TestDecodeSingle(t *testing.T) {
-	for i, m := range txMappings {
+	for i, m := range txMappings() {
 		if m.class == Reserved {
 			continue
 		}
@@ -51,3 +51,68 @@ func TestDecodeIncompleteHalf(t *testing.T) {
 	b, err = dec.Decode(nil, []byte{0x22})
 	assert.Equal(t, []byte{0xe3, 0x80, 0x81}, b)
 }
+
+func TestDecodeControlPassthrough(t *testing.T) {
+	dec := NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+	_, err := dec.Decode(nil, []byte{0x30, 0x21, 0x0a})
+	assert.EqualError(t, err, "unexpected byte \\x0a at offset 2")
+
+	dec = NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+	dec.ControlPassthrough = true
+	b, err := dec.Decode(nil, []byte{0x30, 0x21, 0x0d, 0x0a, 0x0f, 0x21, 0x21, 0x0e, 0x09})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "亜\r\n𠂉\t", string(b))
+	}
+}
+
+func TestDecodeTranslit(t *testing.T) {
+	dec := NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+	dec.Translit = true
+	dec.Edition = JISX0213Edition2000
+	b, err := dec.Decode(nil, []byte{0x30, 0x21, 0x7e, 0x7e, 0x7e, 0x79, 0x24, 0x77})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "亜繋和か゚", string(b))
+	}
+	for _, input := range []string{"ジャンクロードヴァンダム", "ゔゕゖ㉑繫", "かか゚\U00020089"} {
+		enc := NewJNTAJISIncrementalEncoder(ConversionModeSISO, InvalidJISCode)
+		b, err := enc.Encode(nil, input)
+		if assert.NoError(t, err) {
+			b, err = enc.Flush(b)
+		}
+		if assert.NoError(t, err) {
+			dec := NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+			dec.Translit = true
+			result, err := dec.Decode(nil, b)
+			if assert.NoError(t, err) {
+				assert.Equal(t, Transliterate(input), string(result))
+			}
+		}
+	}
+}
+
+func TestDecodePreference(t *testing.T) {
+	in := []byte{0x30, 0x21, 0x30, 0x22}
+	dec := NewJNTAJISDecoder(ConversionModeMen1, InvalidRune)
+	b, err := dec.Decode(nil, in)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "亜唖", string(b))
+	}
+	m := shrinkingTransliterationMapping{
+		rs:  [2]rune{'亜', InvalidRune},
+		srs: [2]rune{'亞', InvalidRune},
+	}
+	assert.Equal(t, m.rs, *dec.preferredRunes(&m))
+	dec.Preference = PreferSimilarGlyph
+	assert.Equal(t, m.srs, *dec.preferredRunes(&m))
+	m.srs = [2]rune{InvalidRune, InvalidRune}
+	assert.Equal(t, m.rs, *dec.preferredRunes(&m))
+	b, err = dec.Decode(nil, in)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "亜唖", string(b))
+	}
+	dec.Preferences = map[rune]rune{'亜': '\ue000', '唖': '啞'}
+	b, err = dec.Decode(nil, in)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "\ue000啞", string(b))
+	}
+}
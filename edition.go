@@ -0,0 +1,62 @@
+package jntajis
+
+import "fmt"
+
+// JISX0213Edition selects the repertoire of JIS X 0213. The zero value is
+// JISX0213Edition2004.
+type JISX0213Edition int
+
+const (
+	JISX0213Edition2004 = JISX0213Edition(iota)
+	JISX0213Edition2000
+)
+
+func (e JISX0213Edition) String() string {
+	switch e {
+	case JISX0213Edition2004:
+		return "JISX0213Edition2004"
+	case JISX0213Edition2000:
+		return "JISX0213Edition2000"
+	default:
+		return fmt.Sprintf("??? (%d)", e)
+	}
+}
+
+// jisx0213Edition2004Additions lists the characters added to the first plane
+// by JIS X 0213:2004, in packed men-ku-ten code.
+var jisx0213Edition2004Additions = [...]uint32{
+	13*94 + 0,  // 1-14-1 U+4FF1
+	14*94 + 93, // 1-15-94 U+525D
+	46*94 + 51, // 1-47-52 U+20B9F
+	46*94 + 93, // 1-47-94 U+541E
+	83*94 + 6,  // 1-84-7 U+5653
+	93*94 + 89, // 1-94-90 U+59F8
+	93*94 + 90, // 1-94-91 U+5C5B
+	93*94 + 91, // 1-94-92 U+5E77
+	93*94 + 92, // 1-94-93 U+7626
+	93*94 + 93, // 1-94-94 U+7E6B
+}
+
+func isJISX0213Edition2004Addition(jis uint32) bool {
+	for _, c := range jisx0213Edition2004Additions {
+		if c == jis {
+			return true
+		}
+	}
+	return false
+}
+
+// EditionOf returns the first edition of JIS X 0213 that contains the
+// character.
+func EditionOf(jis uint32) JISX0213Edition {
+	if isJISX0213Edition2004Addition(jis) {
+		return JISX0213Edition2004
+	}
+	return JISX0213Edition2000
+}
+
+// isUnavailableInEdition reports whether the cell cannot be stored in a system
+// that conforms to the given edition.
+func isUnavailableInEdition(jis uint32, e JISX0213Edition) bool {
+	return e == JISX0213Edition2000 && isJISX0213Edition2004Addition(jis)
+}
@@ -0,0 +1,106 @@
+package jntajis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeEdition(t *testing.T) {
+	cases := []struct {
+		expected []byte
+		err      string
+		mode     ConversionMode
+		edition  JISX0213Edition
+		input    string
+	}{
+		{
+			expected: []byte{0x7e, 0x7e},
+			mode:     ConversionModeMen1,
+			edition:  JISX0213Edition2004,
+			input:    "繫",
+		},
+		{
+			err:     "繫 is not convertible to JISX0208",
+			mode:    ConversionModeMen1,
+			edition: JISX0213Edition2000,
+			input:   "繫",
+		},
+		{
+			expected: []byte{0x37, 0x52},
+			mode:     ConversionModeTranslit,
+			edition:  JISX0213Edition2000,
+			input:    "繫",
+		},
+		{
+			err:     "繫 is not convertible to JISX0208",
+			mode:    ConversionModeJISX0208,
+			edition: JISX0213Edition2000,
+			input:   "繫",
+		},
+		{
+			expected: []byte{0x7e, 0x79},
+			mode:     ConversionModeMen1,
+			edition:  JISX0213Edition2000,
+			input:    "龢",
+		},
+	}
+
+	for i, case_ := range cases {
+		t.Run(fmt.Sprintf("%d: %s %s", i, case_.input, case_.edition), func(t *testing.T) {
+			enc := NewJNTAJISEncoder(case_.mode, InvalidJISCode)
+			enc.Edition = case_.edition
+			result, err := enc.EncodeAsJISX0213Men1(case_.input)
+			if case_.err != "" {
+				assert.EqualError(t, err, case_.err)
+			} else {
+				if assert.NoError(t, err) {
+					assert.Equal(t, case_.expected, result)
+				}
+			}
+		})
+	}
+
+	enc := NewJNTAJISIncrementalEncoder(ConversionModeSISO, InvalidJISCode)
+	enc.Edition = JISX0213Edition2000
+	_, err := enc.Encode(nil, "\U00020089繫")
+	assert.EqualError(t, err, "繫 is not convertible to JISX0208")
+}
+
+func TestDecodeEdition(t *testing.T) {
+	dec := NewJNTAJISDecoder(ConversionModeMen1, InvalidRune)
+	result, err := dec.Decode(nil, []byte{0x7e, 0x7e})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "繫", string(result))
+	}
+	dec = NewJNTAJISDecoder(ConversionModeMen1, '〓')
+	dec.Edition = JISX0213Edition2000
+	result, err = dec.Decode(nil, []byte{0x7e, 0x7e, 0x7e, 0x79})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "〓龢", string(result))
+	}
+}
+
+func TestCharInfoEdition(t *testing.T) {
+	for _, c := range jisx0213Edition2004Additions {
+		ci, ok := LookupCharInfo(c)
+		if assert.True(t, ok) {
+			assert.Equal(t, JISX0213Edition2004, ci.Edition)
+			assert.Equal(t, KanjiLevel3, ci.Class)
+			ci2, ok := LookupCharInfoByRune(ci.Runes[0])
+			if assert.True(t, ok) {
+				assert.Equal(t, ci, ci2)
+			}
+		}
+	}
+	ci, ok := LookupCharInfoByRune('亜')
+	if assert.True(t, ok) {
+		assert.Equal(t, JISX0213Edition2000, ci.Edition)
+		assert.Equal(t, MenKuTen{1, 16, 1}, ci.MenKuTen())
+		assert.Equal(t, "KanjiLevel1", ci.Class.String())
+		assert.Equal(t, []rune{'亜'}, ci.TranslitRunes)
+	}
+	_, ok = LookupCharInfo(MenKuTen{1, 2, 17}.JIS())
+	assert.False(t, ok)
+}
@@ -4,12 +4,18 @@ import "fmt"
 
 const InvalidJISCode = uint32(0xffffffff)
 
+// JNTAJISEncoder holds no state of its own, so it is safe for concurrent use
+// as long as its fields are not modified.
 type JNTAJISEncoder struct {
+	encoderOptions
 	Replacement uint32
 	putJIS      func([]byte, uint32) ([]byte, bool)
 }
 
+// JNTAJISIncrementalEncoder keeps the state across calls to Encode, so it
+// must not be shared between goroutines. See Encode for a safe alternative.
 type JNTAJISIncrementalEncoder struct {
+	encoderOptions
 	Replacement uint32
 	putJIS      func([]byte, uint32) ([]byte, bool)
 	lookahead   []rune
@@ -17,6 +23,16 @@ type JNTAJISIncrementalEncoder struct {
 	state       int
 }
 
+// encoderOptions holds the settings shared by JNTAJISEncoder and
+// JNTAJISIncrementalEncoder.
+type encoderOptions struct {
+	Overrides     *Overrides
+	Gaiji         *GaijiRegistry
+	ReservedAsPUA bool
+	Edition       JISX0213Edition
+	mode          ConversionMode
+}
+
 type ConversionMode int
 
 const (
@@ -41,29 +57,57 @@ func (c ConversionMode) String() string {
 	}
 }
 
-func lookupRevTable(r rune) (uint32, bool) {
-	s, e := 0, len(runeRangeToJISMappings)
-	for s < e && e <= len(runeRangeToJISMappings) {
-		m := (s + e) / 2
-		mm := &runeRangeToJISMappings[m]
-		if r < mm.start {
-			e = m
-			continue
-		} else if r > mm.end {
-			s = m + 1
-			continue
+// putRune looks r up in the overrides, the gaiji registry, the reserved PUA
+// range and then in the NTA table in this order, and puts the resulting JIS
+// code with put.
+func (o *encoderOptions) putRune(b []byte, r rune, put func([]byte, uint32) ([]byte, bool)) ([]byte, bool) {
+	if o.Overrides != nil {
+		if o.mode == ConversionModeTranslit {
+			if tx, ok := o.Overrides.translit[r]; ok {
+				for _, c := range tx {
+					b, _ = putJISMen1(b, c)
+				}
+				return b, true
+			}
+		}
+		if jis, ok := o.Overrides.jis[r]; ok {
+			return put(b, jis)
+		}
+	}
+	if o.Gaiji != nil {
+		if ge, ok := o.Gaiji.byRune[r]; ok {
+			return ge.put(b, o.mode, put)
 		}
-		o := int(r - mm.start)
-		if o >= len(mm.jis) {
-			return 0, false
+	}
+	if o.ReservedAsPUA {
+		if jis, ok := jisForReservedPUA(r); ok {
+			return putReserved(b, jis, o.mode, put)
 		}
-		jis := mm.jis[o]
-		if jis == InvalidJISCode {
-			return 0, false
+	}
+	jis, ok := lookupRevTable(r)
+	if !ok {
+		return b, false
+	}
+	if isUnavailableInEdition(jis, o.Edition) {
+		if o.mode != ConversionModeTranslit {
+			return b, false
 		}
-		return jis, true
+		return putTranslitOnly(b, jis, put)
 	}
-	return 0, false
+	return put(b, jis)
+}
+
+// putTranslitOnly puts the transliterated form of a character, which is
+// always made of JIS X 0208 characters.
+func putTranslitOnly(b []byte, jis uint32, put func([]byte, uint32) ([]byte, bool)) ([]byte, bool) {
+	m := &txMappings()[jis]
+	if m.txLen == 0 {
+		return b, false
+	}
+	for _, c := range m.txJIS[:m.txLen] {
+		b, _ = put(b, c)
+	}
+	return b, true
 }
 
 func putJISMen1(b []byte, c uint32) ([]byte, bool) {
@@ -75,10 +119,10 @@ func putJISMen1(b []byte, c uint32) ([]byte, bool) {
 }
 
 func putJISX0208(b []byte, c uint32) ([]byte, bool) {
-	if int(c) >= len(txMappings) {
+	if int(c) >= len(txMappings()) {
 		return b, false
 	}
-	switch txMappings[c].class {
+	switch txMappings()[c].class {
 	case KanjiLevel1, KanjiLevel2, JISX0208NonKanji:
 		ku0, ten0 := c/94%94, c%94
 		return append(b, byte(0x21+ku0), byte(0x21+ten0)), true
@@ -88,10 +132,10 @@ func putJISX0208(b []byte, c uint32) ([]byte, bool) {
 }
 
 func putJISX0208Translit(b []byte, c uint32) ([]byte, bool) {
-	if int(c) >= len(txMappings) {
+	if int(c) >= len(txMappings()) {
 		return b, false
 	}
-	m := &txMappings[c]
+	m := &txMappings()[c]
 	switch m.class {
 	case KanjiLevel1, KanjiLevel2, JISX0208NonKanji:
 		ku0, ten0 := c/94%94, c%94
@@ -176,6 +220,15 @@ func (e *JNTAJISIncrementalEncoder) Encode(b []byte, m string) ([]byte, error) {
 		} else if e.state == 0 {
 			e.lookahead = append(e.lookahead, r)
 		} else {
+			// r may start another pair right after an unmatched one
+			if len(e.lookahead) > 0 {
+				state := e.state
+				b, err = e.flushLookahead(b)
+				if err != nil {
+					return b, err
+				}
+				e.state = state
+			}
 			e.lookahead = append(e.lookahead, r)
 			continue
 		}
@@ -191,10 +244,8 @@ func (e *JNTAJISIncrementalEncoder) flushLookahead(b []byte) ([]byte, error) {
 	put := e.putJIS
 	for _, r := range e.lookahead {
 		var err error
-		jis, ok := lookupRevTable(r)
-		if ok {
-			b, ok = put(b, jis)
-		}
+		var ok bool
+		b, ok = e.putRune(b, r, put)
 		if !ok {
 			b, err = appendReplacement(b, r, e.Replacement)
 			if err != nil {
@@ -216,75 +267,61 @@ func (e *JNTAJISIncrementalEncoder) Flush(b []byte) ([]byte, error) {
 	return b, nil
 }
 
-func (e *JNTAJISEncoder) EncodeAsJISX0213Men1(m string) ([]byte, error) {
+// encode appends the encoded form of m to b, recording the correspondence in
+// a unless it is nil.
+func (e *JNTAJISEncoder) encode(b []byte, m string, a *Alignment) ([]byte, error) {
 	put := e.putJIS
-	rb := make([]rune, 0, 2)
-	b := make([]byte, 0, len(m))
-	s := 0
-	var jis uint32
-	var err error
-	for _, r := range m {
-		ok := false
-		s, jis = smRuneToJISMapping(s, r)
-		if s == -1 {
-			b, ok = put(b, jis)
-			if !ok {
-				b, err = appendReplacement(b, r, e.Replacement)
-				if err != nil {
-					return b, err
-				}
-			}
-			rb = rb[:0]
-			s = 0
-		} else if s == 0 {
-			rb = append(rb, r)
-		} else {
-			rb = append(rb, r)
-			continue
-		}
-		for _, r := range rb {
-			jis, ok = lookupRevTable(r)
-			if ok {
-				b, ok = put(b, jis)
-			}
-			if !ok {
-				b, err = appendReplacement(b, r, e.Replacement)
-				if err != nil {
-					return b, err
-				}
-			}
-		}
-		rb = rb[:0]
-	}
-	for _, r := range rb {
+	err := forEachSegment(m, func(seg segment) error {
 		var ok bool
-		jis, ok = lookupRevTable(r)
-		if ok {
-			b, ok = put(b, jis)
+		var err error
+		start := len(b)
+		if seg.pair {
+			b, ok = put(b, seg.jis)
+		} else {
+			b, ok = e.putRune(b, seg.r, put)
 		}
 		if !ok {
-			b, err = appendReplacement(b, r, e.Replacement)
+			b, err = appendReplacement(b, seg.last, e.Replacement)
 			if err != nil {
-				return b, err
+				return err
 			}
 		}
-	}
-	return b, nil
+		if a != nil {
+			a.add(&seg, start, len(b), start/2, len(b)/2)
+		}
+		return nil
+	})
+	return b, err
+}
+
+func (e *JNTAJISEncoder) EncodeAsJISX0213Men1(m string) ([]byte, error) {
+	return e.encode(make([]byte, 0, len(m)), m, nil)
+}
+
+// EncodeWithAlignment is like EncodeAsJISX0213Men1 but also returns the
+// alignment between the input and the output. Character indices in the output
+// count JIS characters.
+func (e *JNTAJISEncoder) EncodeWithAlignment(m string) ([]byte, *Alignment, error) {
+	a := new(Alignment)
+	b, err := e.encode(make([]byte, 0, len(m)), m, a)
+	return b, a, err
 }
 
 func NewJNTAJISEncoder(mode ConversionMode, replacement uint32) *JNTAJISEncoder {
 	return &JNTAJISEncoder{
-		Replacement: replacement,
-		putJIS:      putFuncForConversionMode(mode),
+		encoderOptions: encoderOptions{mode: mode},
+		Replacement:    replacement,
+		putJIS:         putFuncForConversionMode(mode),
 	}
 }
 
 func NewJNTAJISIncrementalEncoder(mode ConversionMode, replacement uint32) *JNTAJISIncrementalEncoder {
 	e := &JNTAJISIncrementalEncoder{
-		Replacement: replacement,
-		lookahead:   make([]rune, 0, 2),
-		shiftState:  0,
-		state:       0,
+		encoderOptions: encoderOptions{mode: mode},
+		Replacement:    replacement,
+		lookahead:      make([]rune, 0, 2),
+		shiftState:     0,
+		state:          0,
 	}
 	if mode == ConversionModeSISO {
 		e.putJIS = e.putJISSISO
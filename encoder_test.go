@@ -26,7 +26,7 @@ func runePairToString(p [2]rune) string {
 }
 
 func TestEncodeJISX0213Men1Single(t *testing.T) {
-	for i, m := range txMappings {
+	for i, m := range txMappings() {
 		if m.class == Reserved {
 			continue
 		}
@@ -48,7 +48,7 @@ func TestEncodeJISX0213Men1Single(t *testing.T) {
 }
 
 func TestEncodeJISX0208Single(t *testing.T) {
-	for i, m := range txMappings {
+	for i, m := range txMappings() {
 		if m.class == Reserved {
 			continue
 		}
@@ -225,6 +225,18 @@ func TestEncodeSeqs(t *testing.T) {
 			mode:  ConversionModeTranslit,
 			input: "ジャンクロードヴァンダム",
 		},
+		{
+			expected: []byte{0x24, 0x2b, 0x24, 0x77},
+			err:      "",
+			mode:     ConversionModeMen1,
+			input:    "かか゚",
+		},
+		{
+			expected: []byte{0x24, 0x2b, 0x24, 0x2b, 0x24, 0x2b},
+			err:      "",
+			mode:     ConversionModeMen1,
+			input:    "かかか",
+		},
 	}
 
 	for i, case_ := range cases {
@@ -383,6 +395,20 @@ func TestIncrementalEncodeSeqs(t *testing.T) {
 			mode:            ConversionModeSISO,
 			input:           "\xf0\xa0\x82\x89",
 		},
+		{
+			expected:        []byte{0x24, 0x2b, 0x24, 0x77},
+			expectedAtFlush: []byte{0x24, 0x2b, 0x24, 0x77},
+			err:             "",
+			mode:            ConversionModeMen1,
+			input:           "かか゚",
+		},
+		{
+			expected:        []byte{0x24, 0x2b, 0x24, 0x2b},
+			expectedAtFlush: []byte{0x24, 0x2b, 0x24, 0x2b, 0x24, 0x2b},
+			err:             "",
+			mode:            ConversionModeMen1,
+			input:           "かかか",
+		},
 	}
 
 	for i, case_ := range cases {
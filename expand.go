@@ -0,0 +1,161 @@
+package jntajis
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	shrinkSourcesOnce sync.Once
+	// maps a JIS X 0208 character to the characters that shrink to it alone
+	shrinkSources map[uint32][]uint32
+)
+
+func buildShrinkSources() {
+	shrinkSources = make(map[uint32][]uint32)
+	for i := range txMappings() {
+		m := &txMappings()[i]
+		jis := uint32(i)
+		if m.class == Reserved || isJISX0208(jis) || m.txLen != 1 {
+			continue
+		}
+		shrinkSources[m.txJIS[0]] = append(shrinkSources[m.txJIS[0]], jis)
+	}
+}
+
+func runePairString(p [2]rune) string {
+	return string(appendRunes(nil, p[:]))
+}
+
+// ShrinkExpansions returns, for every character of s, the character itself
+// followed by the characters of JIS X 0213 that are transliterated into it.
+// Only one-to-one transliterations are taken into account.
+func ShrinkExpansions(s string) [][]string {
+	shrinkSourcesOnce.Do(buildShrinkSources)
+	var retval [][]string
+	forEachSegment(s, func(seg segment) error {
+		alts := []string{s[seg.offset : seg.offset+seg.size]}
+		if jis, ok := segmentJIS(&seg); ok {
+			for _, c := range shrinkSources[jis] {
+				alts = append(alts, runePairString(txMappings()[c].rs))
+			}
+		}
+		retval = append(retval, alts)
+		return nil
+	})
+	return retval
+}
+
+func quoteForCharClass(r rune) string {
+	switch r {
+	case '\\', '[', ']', '^', '-':
+		return `\` + string(r)
+	default:
+		return string(r)
+	}
+}
+
+func expansionPattern(alts []string) string {
+	if len(alts) == 1 {
+		return regexp.QuoteMeta(alts[0])
+	}
+	single := true
+	for _, a := range alts {
+		if utf8.RuneCountInString(a) != 1 {
+			single = false
+			break
+		}
+	}
+	var sb strings.Builder
+	if single {
+		sb.WriteByte('[')
+		for _, a := range alts {
+			r, _ := utf8.DecodeRuneInString(a)
+			sb.WriteString(quoteForCharClass(r))
+		}
+		sb.WriteByte(']')
+	} else {
+		sb.WriteString("(?:")
+		for i, a := range alts {
+			if i > 0 {
+				sb.WriteByte('|')
+			}
+			sb.WriteString(regexp.QuoteMeta(a))
+		}
+		sb.WriteByte(')')
+	}
+	return sb.String()
+}
+
+// ShrinkExpansionPatterns returns a regular expression for every character of
+// s that matches the alternatives given by ShrinkExpansions.
+func ShrinkExpansionPatterns(s string) []string {
+	expansions := ShrinkExpansions(s)
+	retval := make([]string, len(expansions))
+	for i, alts := range expansions {
+		retval[i] = expansionPattern(alts)
+	}
+	return retval
+}
+
+// ShrinkExpansionPattern returns a regular expression that matches s and
+// every string that shrinks to s.
+func ShrinkExpansionPattern(s string) string {
+	return strings.Join(ShrinkExpansionPatterns(s), "")
+}
+
+// ShrinkExpansionIterator enumerates the strings that shrink to a string, in
+// lexicographical order of the alternatives given by ShrinkExpansions. The
+// first value is the string itself.
+type ShrinkExpansionIterator struct {
+	expansions [][]string
+	indices    []int
+	limit      int
+	n          int
+	done       bool
+	sb         strings.Builder
+}
+
+// NewShrinkExpansionIterator returns an iterator that yields at most limit
+// candidates, or all of them if limit is not positive.
+func NewShrinkExpansionIterator(s string, limit int) *ShrinkExpansionIterator {
+	expansions := ShrinkExpansions(s)
+	return &ShrinkExpansionIterator{
+		expansions: expansions,
+		indices:    make([]int, len(expansions)),
+		limit:      limit,
+	}
+}
+
+func (it *ShrinkExpansionIterator) advance() bool {
+	for i := len(it.indices) - 1; i >= 0; i-- {
+		it.indices[i]++
+		if it.indices[i] < len(it.expansions[i]) {
+			return true
+		}
+		it.indices[i] = 0
+	}
+	return false
+}
+
+func (it *ShrinkExpansionIterator) Next() bool {
+	if it.done || (it.limit > 0 && it.n >= it.limit) {
+		return false
+	}
+	if it.n > 0 && !it.advance() {
+		it.done = true
+		return false
+	}
+	it.n++
+	it.sb.Reset()
+	for i, alts := range it.expansions {
+		it.sb.WriteString(alts[it.indices[i]])
+	}
+	return true
+}
+
+func (it *ShrinkExpansionIterator) Value() string {
+	return it.sb.String()
+}
@@ -0,0 +1,52 @@
+package jntajis
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShrinkExpansions(t *testing.T) {
+	assert.Equal(
+		t,
+		[][]string{{"高"}, {"橋", "𣘺"}, {"崎", "﨑"}, {"か゚"}, {"1"}},
+		ShrinkExpansions("高橋崎か゚1"),
+	)
+	assert.Equal(
+		t,
+		[]string{"高", "[橋𣘺]", "[崎﨑]", "か゚", "1"},
+		ShrinkExpansionPatterns("高橋崎か゚1"),
+	)
+	assert.Equal(t, "[２⓶❷②]\\*", ShrinkExpansionPattern("２*"))
+
+	re := regexp.MustCompile("^" + ShrinkExpansionPattern("高橋崎") + "$")
+	for _, s := range []string{"高橋崎", "高𣘺崎", "高橋﨑", "高𣘺﨑"} {
+		assert.True(t, re.MatchString(s), s)
+		assert.Equal(t, "高橋崎", Transliterate(s))
+	}
+	assert.False(t, re.MatchString("髙橋崎"))
+}
+
+func TestShrinkExpansionIterator(t *testing.T) {
+	var result []string
+	it := NewShrinkExpansionIterator("橋崎", 0)
+	for it.Next() {
+		result = append(result, it.Value())
+	}
+	assert.Equal(t, []string{"橋崎", "橋﨑", "𣘺崎", "𣘺﨑"}, result)
+
+	result = nil
+	it = NewShrinkExpansionIterator("橋崎", 3)
+	for it.Next() {
+		result = append(result, it.Value())
+	}
+	assert.Equal(t, []string{"橋崎", "橋﨑", "𣘺崎"}, result)
+
+	result = nil
+	it = NewShrinkExpansionIterator("", 0)
+	for it.Next() {
+		result = append(result, it.Value())
+	}
+	assert.Equal(t, []string{""}, result)
+}
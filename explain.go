@@ -0,0 +1,216 @@
+package jntajis
+
+import "fmt"
+
+// MatchKind tells how an input character was looked up.
+type MatchKind int
+
+const (
+	// not found anywhere
+	MatchNone = MatchKind(iota)
+	// found in the NTA table as a single character
+	MatchSingle
+	// found in the NTA table as a combining sequence
+	MatchCombiningPair
+	// found in the NTA table as the secondary (similar glyph) form
+	MatchSimilarGlyph
+	// found in the user-defined overrides
+	MatchOverride
+	// found in the gaiji registry
+	MatchGaiji
+	// found in the range of ReservedPUABase
+	MatchReservedPUA
+)
+
+func (k MatchKind) String() string {
+	switch k {
+	case MatchNone:
+		return "MatchNone"
+	case MatchSingle:
+		return "MatchSingle"
+	case MatchCombiningPair:
+		return "MatchCombiningPair"
+	case MatchSimilarGlyph:
+		return "MatchSimilarGlyph"
+	case MatchOverride:
+		return "MatchOverride"
+	case MatchGaiji:
+		return "MatchGaiji"
+	case MatchReservedPUA:
+		return "MatchReservedPUA"
+	default:
+		return fmt.Sprintf("??? (%d)", k)
+	}
+}
+
+// ConversionAction tells what the encoder emitted for an input character.
+type ConversionAction int
+
+const (
+	// the matched JIS character as is
+	ActionDirect = ConversionAction(iota)
+	// the transliterated form of the matched JIS character
+	ActionTranslit
+	// the replacement character
+	ActionReplacement
+	// nothing; the encoder fails with an error
+	ActionUnconvertible
+)
+
+func (a ConversionAction) String() string {
+	switch a {
+	case ActionDirect:
+		return "ActionDirect"
+	case ActionTranslit:
+		return "ActionTranslit"
+	case ActionReplacement:
+		return "ActionReplacement"
+	case ActionUnconvertible:
+		return "ActionUnconvertible"
+	default:
+		return fmt.Sprintf("??? (%d)", a)
+	}
+}
+
+// Explanation is the record of the decision taken for an input character.
+type Explanation struct {
+	// the input character; a combining sequence for a pair
+	Text string
+	// byte offset in the input
+	Offset int
+	// rune index in the input
+	Index int
+	Match MatchKind
+	// packed men-ku-ten code of the matched JIS character, or InvalidJISCode
+	JIS uint32
+	// Reserved if the character is not in JIS X 0213 at all
+	Class  JISCharacterClass
+	Action ConversionAction
+	// the emitted JIS characters in packed men-ku-ten code
+	Output []uint32
+}
+
+// explainCell mirrors the put functions for the mode.
+func explainCell(jis uint32, mode ConversionMode, edition JISX0213Edition) (ConversionAction, []uint32) {
+	if int(jis) >= len(txMappings()) {
+		return ActionUnconvertible, nil
+	}
+	m := &txMappings()[jis]
+	switch mode {
+	case ConversionModeSISO:
+		if isUnavailableInEdition(jis, edition) {
+			return ActionUnconvertible, nil
+		}
+		return ActionDirect, []uint32{jis}
+	case ConversionModeMen1:
+		if isUnavailableInEdition(jis, edition) || jis >= 94*94 {
+			return ActionUnconvertible, nil
+		}
+		return ActionDirect, []uint32{jis}
+	case ConversionModeJISX0208:
+		if isJISX0208(jis) {
+			return ActionDirect, []uint32{jis}
+		}
+		return ActionUnconvertible, nil
+	case ConversionModeTranslit:
+		if isJISX0208(jis) {
+			return ActionDirect, []uint32{jis}
+		}
+	}
+	if m.txLen > 0 {
+		return ActionTranslit, append([]uint32(nil), m.txJIS[:m.txLen]...)
+	}
+	return ActionUnconvertible, nil
+}
+
+func (o *encoderOptions) explainRune(ex *Explanation, r rune) {
+	if o.Overrides != nil {
+		if o.mode == ConversionModeTranslit {
+			if tx, ok := o.Overrides.translit[r]; ok {
+				ex.Match = MatchOverride
+				ex.Action = ActionTranslit
+				ex.Output = append([]uint32(nil), tx...)
+				return
+			}
+		}
+		if jis, ok := o.Overrides.jis[r]; ok {
+			ex.Match = MatchOverride
+			ex.JIS = jis
+			ex.Class = txMappings()[jis].class
+			ex.Action, ex.Output = explainCell(jis, o.mode, JISX0213Edition2004)
+			return
+		}
+	}
+	if o.Gaiji != nil {
+		if ge, ok := o.Gaiji.byRune[r]; ok {
+			ex.Match = MatchGaiji
+			ex.JIS = ge.jis
+			if o.mode == ConversionModeTranslit && len(ge.translit) > 0 {
+				ex.Action = ActionTranslit
+				ex.Output = append([]uint32(nil), ge.translit...)
+			} else {
+				ex.Action, ex.Output = explainReserved(ge.jis, o.mode)
+			}
+			return
+		}
+	}
+	if o.ReservedAsPUA {
+		if jis, ok := jisForReservedPUA(r); ok {
+			ex.Match = MatchReservedPUA
+			ex.JIS = jis
+			ex.Action, ex.Output = explainReserved(jis, o.mode)
+			return
+		}
+	}
+	jis, ok := lookupRevTable(r)
+	if !ok {
+		ex.Action = ActionUnconvertible
+		return
+	}
+	m := &txMappings()[jis]
+	if m.rs[0] == r {
+		ex.Match = MatchSingle
+	} else {
+		ex.Match = MatchSimilarGlyph
+	}
+	ex.JIS = jis
+	ex.Class = m.class
+	ex.Action, ex.Output = explainCell(jis, o.mode, o.Edition)
+}
+
+func explainReserved(jis uint32, mode ConversionMode) (ConversionAction, []uint32) {
+	if mode != ConversionModeSISO && jis >= 94*94 {
+		return ActionUnconvertible, nil
+	}
+	return ActionDirect, []uint32{jis}
+}
+
+// Explain returns the decision the encoder takes for every character of s
+// without encoding it.
+func (e *JNTAJISEncoder) Explain(s string) []Explanation {
+	var exs []Explanation
+	forEachSegment(s, func(seg segment) error {
+		ex := Explanation{
+			Text:   s[seg.offset : seg.offset+seg.size],
+			Offset: seg.offset,
+			Index:  seg.index,
+			JIS:    InvalidJISCode,
+			Class:  Reserved,
+		}
+		if seg.pair {
+			ex.Match = MatchCombiningPair
+			ex.JIS = seg.jis
+			ex.Class = txMappings()[seg.jis].class
+			ex.Action, ex.Output = explainCell(seg.jis, e.mode, JISX0213Edition2004)
+		} else {
+			e.explainRune(&ex, seg.r)
+		}
+		if ex.Action == ActionUnconvertible && e.Replacement != InvalidJISCode {
+			ex.Action = ActionReplacement
+			ex.Output = []uint32{e.Replacement}
+		}
+		exs = append(exs, ex)
+		return nil
+	})
+	return exs
+}
@@ -0,0 +1,100 @@
+package jntajis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	enc := NewJNTAJISEncoder(ConversionModeTranslit, InvalidJISCode)
+	o := NewOverrides()
+	assert.NoError(t, o.SetTranslit('﨑', MenKuTen{1, 26, 68}.JIS()))
+	enc.Overrides = o
+	result := enc.Explain("亜繫か゚﨑A")
+	assert.Equal(t, []Explanation{
+		{
+			Text:   "亜",
+			Offset: 0,
+			Index:  0,
+			Match:  MatchSingle,
+			JIS:    MenKuTen{1, 16, 1}.JIS(),
+			Class:  KanjiLevel1,
+			Action: ActionDirect,
+			Output: []uint32{MenKuTen{1, 16, 1}.JIS()},
+		},
+		{
+			Text:   "繫",
+			Offset: 3,
+			Index:  1,
+			Match:  MatchSingle,
+			JIS:    MenKuTen{1, 94, 94}.JIS(),
+			Class:  KanjiLevel3,
+			Action: ActionTranslit,
+			Output: []uint32{MenKuTen{1, 23, 50}.JIS()},
+		},
+		{
+			Text:   "か゚",
+			Offset: 6,
+			Index:  2,
+			Match:  MatchCombiningPair,
+			JIS:    MenKuTen{1, 4, 87}.JIS(),
+			Class:  JISX0213NonKanji,
+			Action: ActionUnconvertible,
+		},
+		{
+			Text:   "﨑",
+			Offset: 12,
+			Index:  4,
+			Match:  MatchOverride,
+			JIS:    InvalidJISCode,
+			Class:  Reserved,
+			Action: ActionTranslit,
+			Output: []uint32{MenKuTen{1, 26, 68}.JIS()},
+		},
+		{
+			Text:   "A",
+			Offset: 15,
+			Index:  5,
+			Match:  MatchNone,
+			JIS:    InvalidJISCode,
+			Class:  Reserved,
+			Action: ActionUnconvertible,
+		},
+	}, result)
+}
+
+func TestExplainAgreesWithEncoder(t *testing.T) {
+	inputs := []string{
+		"ジャンクロードヴァンダム",
+		"ゔゕゖ㉑繫",
+		"かか゚\U00020089✋",
+		"\U000f006f\U000f0000",
+	}
+	g := NewGaijiRegistry()
+	assert.NoError(t, g.Register(0xe000, MenKuTen{1, 2, 17}.JIS()))
+	for _, mode := range []ConversionMode{ConversionModeMen1, ConversionModeJISX0208, ConversionModeTranslit} {
+		for _, edition := range []JISX0213Edition{JISX0213Edition2004, JISX0213Edition2000} {
+			for i, input := range inputs {
+				t.Run(fmt.Sprintf("%s %s %d: %s", mode, edition, i, input), func(t *testing.T) {
+					enc := NewJNTAJISEncoder(mode, MenKuTen{1, 2, 14}.JIS())
+					enc.Gaiji = g
+					enc.ReservedAsPUA = true
+					enc.Edition = edition
+					expected, err := enc.EncodeAsJISX0213Men1(input)
+					if !assert.NoError(t, err) {
+						return
+					}
+					var result []byte
+					for _, ex := range enc.Explain(input) {
+						for _, c := range ex.Output {
+							result, _ = putJISMen1(result, c)
+						}
+					}
+					assert.Equal(t, expected, result)
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package jntajis
+
+import "fmt"
+
+// GaijiRegistry maps code points in the Unicode private use area to cells
+// that are reserved in JIS X 0213. A GaijiRegistry must not be modified while
+// an encoder or a decoder is using it.
+type GaijiRegistry struct {
+	byRune map[rune]*gaijiEntry
+	byJIS  map[uint32]*gaijiEntry
+}
+
+type gaijiEntry struct {
+	r        rune
+	jis      uint32
+	translit []uint32
+}
+
+func NewGaijiRegistry() *GaijiRegistry {
+	return &GaijiRegistry{
+		byRune: make(map[rune]*gaijiEntry),
+		byJIS:  make(map[uint32]*gaijiEntry),
+	}
+}
+
+func isPrivateUse(r rune) bool {
+	return r >= 0xe000 && r <= 0xf8ff
+}
+
+// Register associates r with the reserved cell jis. The optional translit is
+// a sequence of JIS X 0208 characters used in ConversionModeTranslit.
+func (g *GaijiRegistry) Register(r rune, jis uint32, translit ...uint32) error {
+	if !isPrivateUse(r) {
+		return fmt.Errorf("%U is not in the private use area", r)
+	}
+	if int(jis) >= len(txMappings()) {
+		return fmt.Errorf("invalid JIS code for %U: %d", r, jis)
+	}
+	if txMappings()[jis].class != Reserved {
+		return fmt.Errorf("%s is not a reserved cell", MenKuTenFromJIS(jis))
+	}
+	for _, c := range translit {
+		if !isJISX0208(c) {
+			return fmt.Errorf("transliteration for %U contains a character not in JISX0208: %s", r, MenKuTenFromJIS(c))
+		}
+	}
+	if _, ok := g.byRune[r]; ok {
+		return fmt.Errorf("%U is already registered", r)
+	}
+	if _, ok := g.byJIS[jis]; ok {
+		return fmt.Errorf("%s is already registered", MenKuTenFromJIS(jis))
+	}
+	ge := &gaijiEntry{
+		r:        r,
+		jis:      jis,
+		translit: append([]uint32(nil), translit...),
+	}
+	g.byRune[r] = ge
+	g.byJIS[jis] = ge
+	return nil
+}
+
+// LookupRune returns the cell registered for r.
+func (g *GaijiRegistry) LookupRune(r rune) (uint32, bool) {
+	ge, ok := g.byRune[r]
+	if !ok {
+		return InvalidJISCode, false
+	}
+	return ge.jis, true
+}
+
+// LookupJIS returns the code point registered for jis.
+func (g *GaijiRegistry) LookupJIS(jis uint32) (rune, bool) {
+	ge, ok := g.byJIS[jis]
+	if !ok {
+		return InvalidRune, false
+	}
+	return ge.r, true
+}
+
+// put emits the gaiji cell, or its transliteration in ConversionModeTranslit
+// if one is given.
+func (ge *gaijiEntry) put(b []byte, mode ConversionMode, put func([]byte, uint32) ([]byte, bool)) ([]byte, bool) {
+	if mode == ConversionModeTranslit && len(ge.translit) > 0 {
+		for _, c := range ge.translit {
+			b, _ = putJISMen1(b, c)
+		}
+		return b, true
+	}
+	return putReserved(b, ge.jis, mode, put)
+}
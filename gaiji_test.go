@@ -0,0 +1,115 @@
+package jntajis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGaijiRegistry(t *testing.T) *GaijiRegistry {
+	g := NewGaijiRegistry()
+	assert.NoError(t, g.Register(0xe000, MenKuTen{1, 2, 17}.JIS()))
+	assert.NoError(t, g.Register(0xe001, MenKuTen{2, 2, 1}.JIS(), MenKuTen{1, 16, 1}.JIS()))
+	return g
+}
+
+func TestGaijiEncode(t *testing.T) {
+	g := newTestGaijiRegistry(t)
+
+	cases := []struct {
+		expected []byte
+		err      string
+		mode     ConversionMode
+		input    string
+	}{
+		{
+			expected: []byte{0x22, 0x31},
+			mode:     ConversionModeMen1,
+			input:    "",
+		},
+		{
+			expected: []byte{0x22, 0x31},
+			mode:     ConversionModeJISX0208,
+			input:    "",
+		},
+		{
+			expected: []byte{0x22, 0x31},
+			mode:     ConversionModeTranslit,
+			input:    "",
+		},
+		{
+			err:   " is not convertible to JISX0208",
+			mode:  ConversionModeMen1,
+			input: "",
+		},
+		{
+			err:   " is not convertible to JISX0208",
+			mode:  ConversionModeJISX0208,
+			input: "",
+		},
+		{
+			expected: []byte{0x30, 0x21},
+			mode:     ConversionModeTranslit,
+			input:    "",
+		},
+		{
+			err:   " is not convertible to JISX0208",
+			mode:  ConversionModeMen1,
+			input: "",
+		},
+	}
+
+	for i, case_ := range cases {
+		t.Run(fmt.Sprintf("%d: %U", i, []rune(case_.input)[0]), func(t *testing.T) {
+			enc := NewJNTAJISEncoder(case_.mode, InvalidJISCode)
+			enc.Gaiji = g
+			result, err := enc.EncodeAsJISX0213Men1(case_.input)
+			if case_.err != "" {
+				assert.EqualError(t, err, case_.err)
+			} else {
+				if assert.NoError(t, err) {
+					assert.Equal(t, case_.expected, result)
+				}
+			}
+		})
+	}
+
+	enc := NewJNTAJISIncrementalEncoder(ConversionModeSISO, InvalidJISCode)
+	enc.Gaiji = g
+	result, err := enc.Encode(nil, "")
+	if assert.NoError(t, err) {
+		result, err = enc.Flush(result)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []byte{0x22, 0x31, 0x0f, 0x22, 0x21, 0x0e}, result)
+		}
+	}
+}
+
+func TestGaijiDecode(t *testing.T) {
+	g := newTestGaijiRegistry(t)
+	dec := NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+	_, err := dec.Decode(nil, []byte{0x22, 0x31})
+	assert.EqualError(t, err, "inconvertible character found at offset 0")
+
+	dec = NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+	dec.Gaiji = g
+	result, err := dec.Decode(nil, []byte{0x22, 0x31, 0x0f, 0x22, 0x21, 0x0e, 0x22, 0x32})
+	assert.EqualError(t, err, "inconvertible character found at offset 6")
+	assert.Equal(t, []byte(""), result)
+}
+
+func TestGaijiRegisterErrors(t *testing.T) {
+	g := newTestGaijiRegistry(t)
+	assert.EqualError(t, g.Register('a', MenKuTen{1, 2, 18}.JIS()), "U+0061 is not in the private use area")
+	assert.EqualError(t, g.Register(0xe002, MenKuTen{1, 1, 1}.JIS()), "1-1-1 is not a reserved cell")
+	assert.EqualError(t, g.Register(0xe000, MenKuTen{1, 2, 18}.JIS()), "U+E000 is already registered")
+	assert.EqualError(t, g.Register(0xe002, MenKuTen{1, 2, 17}.JIS()), "1-2-17 is already registered")
+	assert.EqualError(t, g.Register(0xe002, MenKuTen{1, 2, 18}.JIS(), MenKuTen{1, 2, 17}.JIS()), "transliteration for U+E002 contains a character not in JISX0208: 1-2-17")
+	r, ok := g.LookupJIS(MenKuTen{2, 2, 1}.JIS())
+	assert.True(t, ok)
+	assert.Equal(t, rune(0xe001), r)
+	c, ok := g.LookupRune(0xe000)
+	assert.True(t, ok)
+	assert.Equal(t, MenKuTen{1, 2, 17}.JIS(), c)
+}
@@ -5,8 +5,11 @@
 package main
 
 import (
+	"bytes"
+	"encoding/binary"
 	"flag"
 	"fmt"
+	"hash/crc32"
 	"os"
 	"regexp"
 	"sort"
@@ -22,7 +25,7 @@ const InvalidRune = rune(0x7fffffff)
 
 type JISCharacterClass int
 
-var memoRegexp = regexp.MustCompile("^類似字形([uU]+[0-9a-fA-F]+)は本文字に変換する。")
+var memoRegexp = regexp.MustCompile(`^類似字形([uU]\+[0-9a-fA-F]+)は本文字に変換する。`)
 
 const (
 	Reserved         = JISCharacterClass(0)
@@ -126,65 +129,6 @@ type runeRangeToJISMapping struct {
 	start, end rune
 	jis []uint32
 }
-
-var txMappings = [2 * 94 * 94]shrinkingTransliterationMapping {
-	{{- range .txMappings}}
-	{
-		jis: {{.JIS}},
-		rs: [2]rune{{"{"}}{{range $i, $e := .Rs}}{{if $i|lt 0}}, {{end}}{{.}}{{end}}},
-		srs: [2]rune{{"{"}}{{range $i, $e := .SRs}}{{if $i|lt 0}}, {{end}}{{.}}{{end}}},
-		class: {{.Class | classToName}},
-		txLen: {{.TxLen}},
-		txJIS: [4]uint32{{"{"}}{{range $i, $e := .TxJIS}}{{if $i|lt 0}}, {{end}}{{.}}{{end}}},
-		txRunes: [4]rune{{"{"}}{{range $i, $e := .TxRunes}}{{if $i|lt 0}}, {{end}}{{.}}{{end}}},
-	},
-	{{- end}}
-}
-
-var runeRangeToJISMappings = []runeRangeToJISMapping {
-	{{- range .runeRangeToJISMappings}}
-	{
-		start: {{.Start}},
-		end: {{.End}},
-		jis: []uint32{
-			{{- range .JIS}}
-			{{.}},
-			{{- end}}
-		},
-	},
-	{{- end}}
-}
-
-func smRuneToJISMapping(state int, r rune) (int, uint32) {
-	var final uint32
-reenter:
-	switch state {
-	case 0:
-		if r < {{(index .runePairsToJisMappings 0).R}} || r > {{(index .runePairsToJisMappings ((len .runePairsToJisMappings)|add -1)).R}} {
-			break
-		}
-		switch r {
-		{{- range $i, $m := .runePairsToJisMappings}}
-		case {{$m.R}}:
-			state = {{$i|add 1}}
-		{{- end}}
-		}
-	{{- range $i, $m := .runePairsToJisMappings}}
-	case {{$i|add 1}}:
-		switch r {
-		{{- range $m.N}}
-		case {{index .Rs 1}}:
-			final = {{.JIS}}
-			state = -1
-		{{- end}}
-		default:
-			state = 0
-			goto reenter
-		}
-	{{- end}}
-	}
-	return state, final
-}
 `
 
 func parseMenKuTenRepr(v string) (int, error) {
@@ -207,7 +151,7 @@ func parseMenKuTenRepr(v string) (int, error) {
 
 func parseRuneRepr(v string) (rune, error) {
 	var ucp int
-	_, err := fmt.Sscanf(v, "u+%x", &ucp)
+	_, err := fmt.Sscanf(strings.ToLower(v), "u+%x", &ucp)
 	if err != nil {
 		return InvalidRune, err
 	}
@@ -372,11 +316,62 @@ func readExcelFile(f string) ([]shrinkingTransliterationMapping, error) {
 	return mappings, nil
 }
 
+const tableBlobVersion = 1
+
+type blobWriter struct {
+	bytes.Buffer
+}
+
+func (w *blobWriter) uvarint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+// rune writes r+1, or 0 for InvalidRune.
+func (w *blobWriter) rune(r rune) {
+	if r == InvalidRune {
+		w.uvarint(0)
+	} else {
+		w.uvarint(uint64(r) + 1)
+	}
+}
+
+// writeTableBlob writes the tables in the format read by tableload.go.
+func writeTableBlob(dest string, mappings []shrinkingTransliterationMapping, rm []runeRangeToJISMapping) error {
+	var w blobWriter
+	w.uvarint(uint64(len(mappings)))
+	for _, m := range mappings {
+		w.uvarint(uint64(m.JIS))
+		w.rune(m.Rs[0])
+		w.rune(m.Rs[1])
+		w.rune(m.SRs[0])
+		w.rune(m.SRs[1])
+		w.WriteByte(byte(m.Class))
+		w.WriteByte(m.TxLen)
+		for _, c := range m.TxJIS {
+			w.uvarint(uint64(c))
+		}
+		for _, r := range m.TxRunes {
+			w.uvarint(uint64(r))
+		}
+	}
+	w.uvarint(uint64(len(rm)))
+	for _, r := range rm {
+		w.uvarint(uint64(r.Start))
+		w.uvarint(uint64(r.End))
+		w.uvarint(uint64(len(r.JIS)))
+		for _, c := range r.JIS {
+			// c+1, or 0 for InvalidJISCode
+			w.uvarint(uint64(uint32(c + 1)))
+		}
+	}
+	header := []byte{'J', 'N', 'T', 'A', tableBlobVersion, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(header[5:], crc32.ChecksumIEEE(w.Bytes()))
+	return os.WriteFile(dest, append(header, w.Bytes()...), 0644)
+}
+
 func doIt(dest string, src string, p string) error {
 	t := template.New("").Funcs(map[string]interface{}{
-		"add": func(x, y int) int {
-			return x + y
-		},
 		"classToName": func(class JISCharacterClass) string {
 			return enumToClassNameMap[class]
 		},
@@ -451,33 +446,14 @@ func doIt(dest string, src string, p string) error {
 			})
 		}
 	}
-	type outer struct {
-		R rune
-		N []*shrinkingTransliterationMapping
-	}
-	var rpm []outer
-	{
-	next:
-		for _, m := range x {
-			if m.Class == Reserved {
-				continue
-			}
-			if m.Rs[1] == InvalidRune {
-				continue
-			}
-			r := m.Rs[0]
-			for i, _ := range rpm {
-				if rpm[i].R == r {
-					rpm[i].N = append(rpm[i].N, m)
-					continue next
-				}
-			}
-			rpm = append(rpm, outer{r, []*shrinkingTransliterationMapping{m}})
-		}
+	blobDest := strings.TrimSuffix(dest, ".go") + ".bin"
+	fmt.Fprintf(os.Stderr, "writing %s...\n", blobDest)
+	if err := writeTableBlob(blobDest, mappings, rm); err != nil {
+		return err
 	}
 	return t.Execute(
 		destFile,
-		map[string]interface{}{"package": p, "txMappings": mappings, "runeRangeToJISMappings": rm, "runePairsToJisMappings": rpm},
+		map[string]interface{}{"package": p},
 	)
 }
 
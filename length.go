@@ -0,0 +1,88 @@
+package jntajis
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errStopWalking = errors.New("stop walking")
+
+// encodedCellLen returns the number of JIS characters the cell is put as in
+// the mode.
+func encodedCellLen(jis uint32, mode ConversionMode) (int, bool) {
+	if !isRepresentable(jis, mode) {
+		return 0, false
+	}
+	if mode == ConversionModeTranslit && !isJISX0208(jis) {
+		return int(txMappings()[jis].txLen), true
+	}
+	return 1, true
+}
+
+// walkEncodedLen calls f with the end offset of every character of s and the
+// number of bytes needed to encode s up to there, including the shift needed
+// to get back to the first plane in ConversionModeSISO. It stops when f
+// returns false.
+func walkEncodedLen(s string, mode ConversionMode, f func(end int, n int) bool) error {
+	n := 0
+	plane := uint32(0)
+	err := forEachSegment(s, func(seg segment) error {
+		jis, ok := segmentJIS(&seg)
+		var l int
+		if ok {
+			l, ok = encodedCellLen(jis, mode)
+		}
+		if !ok {
+			return fmt.Errorf("%c is not convertible to JISX0208", seg.last)
+		}
+		n += l * 2
+		if mode == ConversionModeSISO && jis/(94*94) != plane {
+			plane = jis / (94 * 94)
+			n++
+		}
+		closing := 0
+		if plane != 0 {
+			closing = 1
+		}
+		if !f(seg.offset+seg.size, n+closing) {
+			return errStopWalking
+		}
+		return nil
+	})
+	if err == errStopWalking {
+		return nil
+	}
+	return err
+}
+
+// EncodedLen returns the length of s encoded in the mode, using the NTA table
+// only. It fails on the first character that cannot be represented.
+func EncodedLen(s string, mode ConversionMode) (int, error) {
+	retval := 0
+	err := walkEncodedLen(s, mode, func(_ int, n int) bool {
+		retval = n
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return retval, nil
+}
+
+// TruncateToFit returns the longest prefix of s whose encoded form in the
+// mode fits in maxBytes. It never splits a combining sequence and accounts for
+// the shift back to the first plane in ConversionModeSISO.
+func TruncateToFit(s string, maxBytes int, mode ConversionMode) (string, error) {
+	end := 0
+	err := walkEncodedLen(s, mode, func(e int, n int) bool {
+		if n > maxBytes {
+			return false
+		}
+		end = e
+		return true
+	})
+	if err != nil {
+		return "", err
+	}
+	return s[:end], nil
+}
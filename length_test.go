@@ -0,0 +1,113 @@
+package jntajis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodedLen(t *testing.T) {
+	inputs := []string{
+		"",
+		"ジャンクロードヴァンダム",
+		"かか゚ゔ㉑",
+		"山\U00020089\U00020089亜\U00020089",
+	}
+	for _, mode := range []ConversionMode{ConversionModeSISO, ConversionModeMen1, ConversionModeJISX0208, ConversionModeTranslit} {
+		for i, input := range inputs {
+			t.Run(fmt.Sprintf("%s %d: %s", mode, i, input), func(t *testing.T) {
+				enc := NewJNTAJISIncrementalEncoder(mode, InvalidJISCode)
+				expected, err := enc.Encode(nil, input)
+				if err == nil {
+					expected, err = enc.Flush(expected)
+				}
+				result, lerr := EncodedLen(input, mode)
+				if err != nil {
+					assert.EqualError(t, lerr, err.Error())
+				} else if assert.NoError(t, lerr) {
+					assert.Equal(t, len(expected), result)
+				}
+			})
+		}
+	}
+}
+
+func TestEncodedLenAllocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		EncodedLen("山\U00020089か゚亜㉑", ConversionModeSISO)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func TestTruncateToFit(t *testing.T) {
+	cases := []struct {
+		expected string
+		err      string
+		mode     ConversionMode
+		maxBytes int
+		input    string
+	}{
+		{
+			expected: "山崎",
+			mode:     ConversionModeJISX0208,
+			maxBytes: 5,
+			input:    "山崎ジャン",
+		},
+		{
+			expected: "",
+			mode:     ConversionModeJISX0208,
+			maxBytes: 1,
+			input:    "山崎ジャン",
+		},
+		{
+			expected: "か",
+			mode:     ConversionModeMen1,
+			maxBytes: 3,
+			input:    "かか゚",
+		},
+		{
+			expected: "山",
+			mode:     ConversionModeTranslit,
+			maxBytes: 5,
+			input:    "山㉑",
+		},
+		{
+			expected: "山",
+			mode:     ConversionModeSISO,
+			maxBytes: 5,
+			input:    "山\U00020089",
+		},
+		{
+			expected: "山\U00020089",
+			mode:     ConversionModeSISO,
+			maxBytes: 6,
+			input:    "山\U00020089亜",
+		},
+		{
+			expected: "山\U00020089亜",
+			mode:     ConversionModeSISO,
+			maxBytes: 8,
+			input:    "山\U00020089亜",
+		},
+		{
+			err:      "\U00020089 is not convertible to JISX0208",
+			mode:     ConversionModeMen1,
+			maxBytes: 8,
+			input:    "山\U00020089亜",
+		},
+	}
+
+	for i, case_ := range cases {
+		t.Run(fmt.Sprintf("%d: %s", i, case_.input), func(t *testing.T) {
+			result, err := TruncateToFit(case_.input, case_.maxBytes, case_.mode)
+			if case_.err != "" {
+				assert.EqualError(t, err, case_.err)
+			} else {
+				if assert.NoError(t, err) {
+					assert.Equal(t, case_.expected, result)
+				}
+			}
+		})
+	}
+}
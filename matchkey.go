@@ -0,0 +1,95 @@
+package jntajis
+
+import "strings"
+
+const (
+	halfwidthKatakana = "。「」、・ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン゛゜"
+	voicedKanaPairs   = "かがきぎくぐけげこごさざしじすずせぜそぞただちぢつづてでとどはばひびふぶへべほぼうゔゝゞカガキギクグケゲコゴサザシジスズセゼソゾタダチヂツヅテデトドハバヒビフブヘベホボウヴワヷヰヸヱヹヲヺヽヾ"
+	semiVoicedPairs   = "はぱひぴふぷへぺほぽハパヒピフプヘペホポ"
+)
+
+var (
+	halfwidthKatakanaRunes = []rune(halfwidthKatakana)
+	voicedKana             = runePairsToMap(voicedKanaPairs)
+	semiVoicedKana         = runePairsToMap(semiVoicedPairs)
+)
+
+func runePairsToMap(s string) map[rune]rune {
+	rs := []rune(s)
+	retval := make(map[rune]rune, len(rs)/2)
+	for i := 0; i+1 < len(rs); i += 2 {
+		retval[rs[i]] = rs[i+1]
+	}
+	return retval
+}
+
+// composeKana widens halfwidth katakana and merges voiced and semi-voiced
+// sound marks into the preceding kana where a precomposed form exists.
+func composeKana(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	var prev rune = -1
+	flush := func() {
+		if prev >= 0 {
+			sb.WriteRune(prev)
+		}
+	}
+	for _, r := range s {
+		if r >= 0xff61 && r <= 0xff9f {
+			r = halfwidthKatakanaRunes[r-0xff61]
+		}
+		var composed rune
+		var ok bool
+		switch r {
+		case 0x3099, 0x309b:
+			composed, ok = voicedKana[prev]
+		case 0x309a, 0x309c:
+			composed, ok = semiVoicedKana[prev]
+		}
+		if ok {
+			prev = composed
+			continue
+		}
+		flush()
+		prev = r
+	}
+	flush()
+	return sb.String()
+}
+
+// foldRune folds fullwidth ASCII variants and the ideographic space into
+// ASCII, and hiragana into katakana.
+func foldRune(r rune) rune {
+	switch {
+	case r >= 0xff01 && r <= 0xff5e:
+		return r - 0xfee0
+	case r == 0x3000:
+		return ' '
+	case r >= 0x3041 && r <= 0x3096, r == 0x309d, r == 0x309e:
+		return r + 0x60
+	default:
+		return r
+	}
+}
+
+// MatchKey returns the key under which two names are considered the same
+// under the JNTA rules: halfwidth katakana is widened, sound marks are
+// composed, similar glyphs are folded, the characters outside JIS X 0208 are
+// transliterated, and then fullwidth ASCII variants are folded into ASCII and
+// hiragana into katakana.
+func MatchKey(s string) string {
+	t := Transliterate(composeKana(s))
+	b := make([]byte, 0, len(t))
+	for _, r := range t {
+		b = appendRune(b, foldRune(r))
+	}
+	return string(b)
+}
+
+// EqualShrunk reports whether a and b have the same MatchKey.
+func EqualShrunk(a, b string) bool {
+	if a == b {
+		return true
+	}
+	return MatchKey(a) == MatchKey(b)
+}
@@ -0,0 +1,60 @@
+package jntajis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchKey(t *testing.T) {
+	assert.Equal(t, 0xff9f-0xff61+1, len(halfwidthKatakanaRunes))
+
+	cases := []struct {
+		expected string
+		input    string
+	}{
+		{
+			expected: "ヤマザキ",
+			input:    "やまざき",
+		},
+		{
+			expected: "ヤマザキ",
+			input:    "ﾔﾏｻﾞｷ",
+		},
+		{
+			expected: "ヤマザキ",
+			input:    "やまさ\u3099き",
+		},
+		{
+			expected: "ポ",
+			input:    "ﾎﾟ",
+		},
+		{
+			expected: "山崎 21",
+			input:    "山﨑　㉑",
+		},
+		{
+			expected: "ABC-1",
+			input:    "ＡＢＣ－１",
+		},
+		{
+			expected: "カ゚",
+			input:    "か゚",
+		},
+	}
+
+	for i, case_ := range cases {
+		t.Run(fmt.Sprintf("%d: %s", i, case_.input), func(t *testing.T) {
+			assert.Equal(t, case_.expected, MatchKey(case_.input))
+		})
+	}
+}
+
+func TestEqualShrunk(t *testing.T) {
+	assert.True(t, EqualShrunk("山﨑", "山崎"))
+	assert.True(t, EqualShrunk("ﾔﾏｻﾞｷ", "やまざき"))
+	assert.True(t, EqualShrunk("繫", "繋"))
+	assert.False(t, EqualShrunk("山崎", "山埼"))
+	assert.False(t, EqualShrunk("ガ", "カ"))
+}
@@ -0,0 +1,73 @@
+package jntajis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MenKuTen is the unpacked form of a JIS X 0213 code point.
+type MenKuTen struct {
+	Men, Ku, Ten int
+}
+
+func MenKuTenFromJIS(jis uint32) MenKuTen {
+	return MenKuTen{
+		Men: int(jis/(94*94)) + 1,
+		Ku:  int(jis/94%94) + 1,
+		Ten: int(jis%94) + 1,
+	}
+}
+
+func (m MenKuTen) IsValid() bool {
+	return m.Men >= 1 && m.Men <= 2 && m.Ku >= 1 && m.Ku <= 94 && m.Ten >= 1 && m.Ten <= 94
+}
+
+// JIS returns the packed men-ku-ten code, or InvalidJISCode if m is out of range.
+func (m MenKuTen) JIS() uint32 {
+	if !m.IsValid() {
+		return InvalidJISCode
+	}
+	return uint32((m.Men-1)*94*94 + (m.Ku-1)*94 + (m.Ten - 1))
+}
+
+func (m MenKuTen) String() string {
+	return fmt.Sprintf("%d-%d-%d", m.Men, m.Ku, m.Ten)
+}
+
+func ParseMenKuTen(v string) (MenKuTen, error) {
+	var m MenKuTen
+	_, err := fmt.Sscanf(v, "%d-%d-%d", &m.Men, &m.Ku, &m.Ten)
+	if err != nil {
+		return m, fmt.Errorf("invalid men-ku-ten representation %q: %w", v, err)
+	}
+	if m.Men < 1 || m.Men > 2 {
+		return m, fmt.Errorf("invalid men value: %d", m.Men)
+	}
+	if m.Ku < 1 || m.Ku > 94 {
+		return m, fmt.Errorf("invalid ku value: %d", m.Ku)
+	}
+	if m.Ten < 1 || m.Ten > 94 {
+		return m, fmt.Errorf("invalid ten value: %d", m.Ten)
+	}
+	return m, nil
+}
+
+// parseRuneRepr accepts either a "U+XXXX" notation or a single literal character.
+func parseRuneRepr(v string) (rune, error) {
+	if len(v) > 2 && (v[0] == 'U' || v[0] == 'u') && v[1] == '+' {
+		var ucp int
+		_, err := fmt.Sscanf(strings.ToLower(v), "u+%x", &ucp)
+		if err != nil {
+			return InvalidRune, fmt.Errorf("invalid code point representation %q: %w", v, err)
+		}
+		if ucp < 0 || ucp > 0x10ffff {
+			return InvalidRune, fmt.Errorf("invalid unicode code point: %08x", ucp)
+		}
+		return rune(ucp), nil
+	}
+	rs := []rune(v)
+	if len(rs) != 1 {
+		return InvalidRune, fmt.Errorf("%q does not represent a single character", v)
+	}
+	return rs[0], nil
+}
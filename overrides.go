@@ -0,0 +1,176 @@
+package jntajis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Overrides holds user-defined mappings that take precedence over the NTA
+// table. An Overrides must not be modified while an encoder is using it.
+type Overrides struct {
+	jis      map[rune]uint32
+	translit map[rune][]uint32
+}
+
+func NewOverrides() *Overrides {
+	return &Overrides{
+		jis:      make(map[rune]uint32),
+		translit: make(map[rune][]uint32),
+	}
+}
+
+// NewOverridesFromMaps builds an Overrides from rune to packed men-ku-ten
+// mappings. Either of the maps may be nil.
+func NewOverridesFromMaps(jis map[rune]uint32, translit map[rune][]uint32) (*Overrides, error) {
+	o := NewOverrides()
+	for r, c := range jis {
+		if err := o.SetJIS(r, c); err != nil {
+			return nil, err
+		}
+	}
+	for r, cs := range translit {
+		if err := o.SetTranslit(r, cs...); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// SetJIS maps r to the given JIS code in every conversion mode.
+func (o *Overrides) SetJIS(r rune, jis uint32) error {
+	if int(jis) >= len(txMappings()) {
+		return fmt.Errorf("invalid JIS code for %U: %d", r, jis)
+	}
+	o.jis[r] = jis
+	return nil
+}
+
+// SetTranslit maps r to the given sequence of JIS X 0208 characters in
+// ConversionModeTranslit.
+func (o *Overrides) SetTranslit(r rune, jis ...uint32) error {
+	if len(jis) == 0 {
+		return fmt.Errorf("empty transliteration for %U", r)
+	}
+	for _, c := range jis {
+		if !isJISX0208(c) {
+			return fmt.Errorf("transliteration for %U contains a character not in JISX0208: %s", r, MenKuTenFromJIS(c))
+		}
+	}
+	o.translit[r] = append([]uint32(nil), jis...)
+	return nil
+}
+
+// LoadCSV reads records of the form "rune,men-ku-ten,men-ku-ten ..." where
+// the rune is either a literal character or in U+XXXX notation, the second
+// field is the JIS code for every mode, and the third field is a
+// space-separated transliteration. Either of the last two fields may be empty.
+// Lines starting with # are ignored.
+func (o *Overrides) LoadCSV(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	for n := 1; ; n++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(rec) < 2 || len(rec) > 3 {
+			return fmt.Errorf("unexpected number of fields at record %d", n)
+		}
+		ch, err := parseRuneRepr(rec[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse rune at record %d: %w", n, err)
+		}
+		if rec[1] != "" {
+			m, err := ParseMenKuTen(rec[1])
+			if err != nil {
+				return fmt.Errorf("failed to parse men-ku-ten at record %d: %w", n, err)
+			}
+			if err = o.SetJIS(ch, m.JIS()); err != nil {
+				return fmt.Errorf("%w at record %d", err, n)
+			}
+		}
+		if len(rec) == 3 && rec[2] != "" {
+			tx, err := parseMenKuTenSeq(strings.Fields(rec[2]))
+			if err != nil {
+				return fmt.Errorf("failed to parse men-ku-ten at record %d: %w", n, err)
+			}
+			if err = o.SetTranslit(ch, tx...); err != nil {
+				return fmt.Errorf("%w at record %d", err, n)
+			}
+		}
+	}
+	return nil
+}
+
+type overridesJSON struct {
+	JIS      map[string]string   `json:"jis"`
+	Translit map[string][]string `json:"translit"`
+}
+
+// LoadJSON reads an object of the form
+// {"jis": {"髙": "1-25-66"}, "translit": {"U+9AD9": ["1-25-66"]}}.
+func (o *Overrides) LoadJSON(r io.Reader) error {
+	var v overridesJSON
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return err
+	}
+	for k, c := range v.JIS {
+		ch, err := parseRuneRepr(k)
+		if err != nil {
+			return err
+		}
+		m, err := ParseMenKuTen(c)
+		if err != nil {
+			return err
+		}
+		if err = o.SetJIS(ch, m.JIS()); err != nil {
+			return err
+		}
+	}
+	for k, cs := range v.Translit {
+		ch, err := parseRuneRepr(k)
+		if err != nil {
+			return err
+		}
+		tx, err := parseMenKuTenSeq(cs)
+		if err != nil {
+			return err
+		}
+		if err = o.SetTranslit(ch, tx...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseMenKuTenSeq(vs []string) ([]uint32, error) {
+	retval := make([]uint32, 0, len(vs))
+	for _, v := range vs {
+		m, err := ParseMenKuTen(v)
+		if err != nil {
+			return nil, err
+		}
+		retval = append(retval, m.JIS())
+	}
+	return retval, nil
+}
+
+func isJISX0208(c uint32) bool {
+	if int(c) >= len(txMappings()) {
+		return false
+	}
+	switch txMappings()[c].class {
+	case KanjiLevel1, KanjiLevel2, JISX0208NonKanji:
+		return true
+	default:
+		return false
+	}
+}
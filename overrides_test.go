@@ -0,0 +1,135 @@
+package jntajis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMenKuTen(t *testing.T) {
+	cases := []struct {
+		expected MenKuTen
+		err      string
+		input    string
+	}{
+		{
+			expected: MenKuTen{1, 25, 66},
+			input:    "1-25-66",
+		},
+		{
+			expected: MenKuTen{2, 94, 86},
+			input:    "2-94-86",
+		},
+		{
+			err:   "invalid men value: 3",
+			input: "3-1-1",
+		},
+		{
+			err:   "invalid ten value: 95",
+			input: "1-1-95",
+		},
+	}
+
+	for i, case_ := range cases {
+		t.Run(fmt.Sprintf("%d: %s", i, case_.input), func(t *testing.T) {
+			result, err := ParseMenKuTen(case_.input)
+			if case_.err != "" {
+				assert.EqualError(t, err, case_.err)
+			} else {
+				if assert.NoError(t, err) {
+					assert.Equal(t, case_.expected, result)
+					assert.Equal(t, case_.input, result.String())
+					assert.Equal(t, result, MenKuTenFromJIS(result.JIS()))
+				}
+			}
+		})
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	o, err := NewOverridesFromMaps(
+		map[rune]uint32{'✋': MenKuTen{1, 2, 1}.JIS()},
+		map[rune][]uint32{'﨑': {MenKuTen{1, 16, 1}.JIS(), MenKuTen{1, 16, 2}.JIS()}},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cases := []struct {
+		expected []byte
+		mode     ConversionMode
+		input    string
+	}{
+		{
+			expected: []byte{0x22, 0x21},
+			mode:     ConversionModeMen1,
+			input:    "✋",
+		},
+		{
+			expected: []byte{0x22, 0x21},
+			mode:     ConversionModeTranslit,
+			input:    "✋",
+		},
+		{
+			expected: []byte{0x30, 0x21, 0x30, 0x22},
+			mode:     ConversionModeTranslit,
+			input:    "﨑",
+		},
+		{
+			expected: []byte{0x4f, 0x72},
+			mode:     ConversionModeMen1,
+			input:    "﨑",
+		},
+		{
+			expected: []byte{0x25, 0x38, 0x30, 0x21, 0x30, 0x22},
+			mode:     ConversionModeTranslit,
+			input:    "ジ﨑",
+		},
+	}
+
+	for i, case_ := range cases {
+		t.Run(fmt.Sprintf("%d: %s", i, case_.input), func(t *testing.T) {
+			enc := NewJNTAJISEncoder(case_.mode, InvalidJISCode)
+			enc.Overrides = o
+			result, err := enc.EncodeAsJISX0213Men1(case_.input)
+			if assert.NoError(t, err) {
+				assert.Equal(t, case_.expected, result)
+			}
+			ienc := NewJNTAJISIncrementalEncoder(case_.mode, InvalidJISCode)
+			ienc.Overrides = o
+			result, err = ienc.Encode(nil, case_.input)
+			if assert.NoError(t, err) {
+				result, err = ienc.Flush(result)
+				if assert.NoError(t, err) {
+					assert.Equal(t, case_.expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestOverridesLoad(t *testing.T) {
+	csvInput := "# house rules\nU+FA11,,1-16-1 1-16-2\n✋,1-2-1\n"
+	jsonInput := `{"jis": {"✋": "1-2-1"}, "translit": {"U+FA11": ["1-16-1", "1-16-2"]}}`
+	for _, load := range []func(o *Overrides) error{
+		func(o *Overrides) error { return o.LoadCSV(strings.NewReader(csvInput)) },
+		func(o *Overrides) error { return o.LoadJSON(strings.NewReader(jsonInput)) },
+	} {
+		o := NewOverrides()
+		if assert.NoError(t, load(o)) {
+			assert.Equal(t, map[rune]uint32{'✋': 94}, o.jis)
+			assert.Equal(t, map[rune][]uint32{'﨑': {1410, 1411}}, o.translit)
+		}
+	}
+}
+
+func TestOverridesErrors(t *testing.T) {
+	o := NewOverrides()
+	assert.EqualError(t, o.SetJIS('a', 2*94*94), "invalid JIS code for U+0061: 17672")
+	assert.EqualError(t, o.SetTranslit('a'), "empty transliteration for U+0061")
+	assert.EqualError(t, o.SetTranslit('a', MenKuTen{1, 14, 1}.JIS()), "transliteration for U+0061 contains a character not in JISX0208: 1-14-1")
+	assert.EqualError(t, o.LoadCSV(strings.NewReader("a,1-1-1,1-1-1,1-1-1\n")), "unexpected number of fields at record 1")
+	assert.EqualError(t, o.LoadCSV(strings.NewReader("a,1-1-1\nab,1-1-1\n")), "failed to parse rune at record 2: \"ab\" does not represent a single character")
+}
@@ -0,0 +1,228 @@
+package jntajis
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"unicode/utf8"
+)
+
+const defaultParallelChunkSize = 1 << 20
+
+// ParallelOptions controls EncodeParallel and DecodeParallel.
+type ParallelOptions struct {
+	// approximate size of a chunk of the input in bytes; 1 MiB if zero
+	ChunkSize int
+	// number of goroutines; runtime.GOMAXPROCS(0) if zero
+	Workers int
+}
+
+func (o *ParallelOptions) chunkSize() int {
+	if o.ChunkSize > 0 {
+		return o.ChunkSize
+	}
+	return defaultParallelChunkSize
+}
+
+func (o *ParallelOptions) workers() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// runChunks calls f for every chunk on a pool of goroutines. When a chunk
+// fails, the chunks after it are skipped, and the error of the earliest
+// failing chunk in the input is returned as a sequential run would.
+func runChunks(ctx context.Context, n int, workers int, f func(i int) ([]byte, error)) ([][]byte, error) {
+	results := make([][]byte, n)
+	errs := make([]error, n)
+	done := make([]bool, n)
+	indices := make(chan int)
+	var mu sync.Mutex
+	// the lowest index of the failed chunks, or n
+	failed := n
+	fail := func(i int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs[i] = err
+		if i < failed {
+			failed = i
+		}
+	}
+	skip := func(i int) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return i > failed
+	}
+	var wg sync.WaitGroup
+	if workers > n {
+		workers = n
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if ctx.Err() != nil || skip(i) {
+					continue
+				}
+				b, err := f(i)
+				if err != nil {
+					fail(i, err)
+					continue
+				}
+				results[i] = b
+				done[i] = true
+			}
+		}()
+	}
+outer:
+	for i := 0; i < n && !skip(i); i++ {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			break outer
+		}
+	}
+	close(indices)
+	wg.Wait()
+	for i := range results {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if !done[i] {
+			// skipped because ctx is done
+			return nil, ctx.Err()
+		}
+	}
+	return results, nil
+}
+
+// splitUTF8 splits s at about every size bytes, never inside a character or
+// after the first character of a possible combining pair.
+func splitUTF8(s string, size int) []int {
+	bounds := []int{0}
+	for o := size; o < len(s); {
+		for o < len(s) && !utf8.RuneStart(s[o]) {
+			o++
+		}
+		for o < len(s) {
+			r, _ := utf8.DecodeLastRuneInString(s[:o])
+			if state, _ := smRuneToJISMapping(0, r); state <= 0 {
+				break
+			}
+			_, n := utf8.DecodeRuneInString(s[o:])
+			o += n
+		}
+		if o >= len(s) {
+			break
+		}
+		bounds = append(bounds, o)
+		o += size
+	}
+	return append(bounds, len(s))
+}
+
+// EncodeParallel encodes s in chunks concurrently with copies of enc, whose
+// own state is left untouched, and returns the same bytes as a single call to
+// Encode followed by Flush.
+func EncodeParallel(ctx context.Context, enc *JNTAJISIncrementalEncoder, s string, opts ParallelOptions) ([]byte, error) {
+	bounds := splitUTF8(s, opts.chunkSize())
+	results, err := runChunks(ctx, len(bounds)-1, opts.workers(), func(i int) ([]byte, error) {
+		e := enc.Clone()
+		e.Reset()
+		chunk := s[bounds[i]:bounds[i+1]]
+		b, err := e.Encode(make([]byte, 0, len(chunk)), chunk)
+		if err != nil {
+			return nil, err
+		}
+		return e.Flush(b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	for _, b := range results {
+		n += len(b)
+	}
+	retval := make([]byte, 0, n)
+	for _, b := range results {
+		// a chunk that ends in the second plane is followed by SO, which
+		// is redundant if the next one starts in the second plane
+		if enc.mode == ConversionModeSISO && len(retval) > 0 && len(b) > 0 && retval[len(retval)-1] == 0x0e && b[0] == 0x0f {
+			retval = retval[:len(retval)-1]
+			b = b[1:]
+		}
+		retval = append(retval, b...)
+	}
+	return retval, nil
+}
+
+type jisChunk struct {
+	start, end  int
+	shiftOffset int
+}
+
+// splitJIS splits in at about every size bytes, never inside a character,
+// recording the shift state at the start of each chunk.
+func splitJIS(in []byte, siso bool, size int) []jisChunk {
+	var chunks []jisChunk
+	c := jisChunk{}
+	shiftOffset := 0
+	i := 0
+	for i < len(in) {
+		if i-c.start >= size {
+			c.end = i
+			chunks = append(chunks, c)
+			c = jisChunk{start: i, shiftOffset: shiftOffset}
+		}
+		c0 := in[i]
+		if c0 >= 0x21 && c0 <= 0x7e {
+			i += 2
+			continue
+		}
+		if siso && c0 == 0x0e {
+			shiftOffset = 0
+		} else if siso && c0 == 0x0f {
+			shiftOffset = 94 * 94
+		}
+		i += 1
+	}
+	c.end = len(in)
+	return append(chunks, c)
+}
+
+// DecodeParallel decodes in in chunks concurrently with copies of dec, whose
+// own state is left untouched. Unlike Decode, it fails if in ends with an
+// incomplete character.
+func DecodeParallel(ctx context.Context, dec *JNTAJISDecoder, in []byte, opts ParallelOptions) ([]byte, error) {
+	chunks := splitJIS(in, dec.siso, opts.chunkSize())
+	results, err := runChunks(ctx, len(chunks), opts.workers(), func(i int) ([]byte, error) {
+		c := &chunks[i]
+		d := dec.Clone()
+		d.Reset()
+		d.shiftOffset = c.shiftOffset
+		b, err := d.decode(make([]byte, 0, (c.end-c.start)*2), in[:c.end], c.start)
+		if err != nil {
+			return nil, err
+		}
+		if d.upper > 0 {
+			return nil, fmt.Errorf("incomplete character at offset %d", c.end-1)
+		}
+		return b, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	for _, b := range results {
+		n += len(b)
+	}
+	retval := make([]byte, 0, n)
+	for _, b := range results {
+		retval = append(retval, b...)
+	}
+	return retval, nil
+}
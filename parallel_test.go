@@ -0,0 +1,103 @@
+package jntajis
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeParallel(t *testing.T) {
+	input := strings.Repeat("山\U00020089か゚かか゚˩˥˩ジャンクロードヴァンダム\U00020089\U00020089", 50)
+	for _, mode := range []ConversionMode{ConversionModeSISO, ConversionModeMen1, ConversionModeJISX0208, ConversionModeTranslit} {
+		for _, chunkSize := range []int{1, 7, 64, 0} {
+			t.Run(fmt.Sprintf("%s %d", mode, chunkSize), func(t *testing.T) {
+				enc := NewJNTAJISIncrementalEncoder(mode, MenKuTen{1, 2, 14}.JIS())
+				expected, err := enc.Encode(nil, input)
+				if assert.NoError(t, err) {
+					expected, err = enc.Flush(expected)
+				}
+				if !assert.NoError(t, err) {
+					return
+				}
+				result, err := EncodeParallel(context.Background(), enc, input, ParallelOptions{ChunkSize: chunkSize, Workers: 4})
+				if assert.NoError(t, err) {
+					assert.Equal(t, expected, result)
+				}
+			})
+		}
+	}
+}
+
+func TestEncodeParallelErrors(t *testing.T) {
+	enc := NewJNTAJISIncrementalEncoder(ConversionModeJISX0208, InvalidJISCode)
+	_, err := EncodeParallel(context.Background(), enc, strings.Repeat("亜", 100)+"\U00020089", ParallelOptions{ChunkSize: 16})
+	assert.EqualError(t, err, "\U00020089 is not convertible to JISX0208")
+
+	// the error is the one of the earliest chunk even if a later one fails
+	// first
+	input := strings.Repeat("亜", 100000) + "\U00020089" + "繫"
+	_, expected := enc.Encode(nil, input)
+	if assert.Error(t, expected) {
+		for i := 0; i < 10; i++ {
+			_, err = EncodeParallel(context.Background(), enc, input, ParallelOptions{ChunkSize: len(input) - len("繫"), Workers: 4})
+			assert.Equal(t, expected, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = EncodeParallel(ctx, enc, strings.Repeat("亜", 100), ParallelOptions{ChunkSize: 16})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestDecodeParallel(t *testing.T) {
+	input := strings.Repeat("山\U00020089か゚\U00020089\U00020089ジャンク\n", 50)
+	enc := NewJNTAJISIncrementalEncoder(ConversionModeSISO, InvalidJISCode)
+	var in []byte
+	for _, line := range strings.SplitAfter(input, "\n") {
+		b, err := enc.Encode(in, strings.TrimSuffix(line, "\n"))
+		if assert.NoError(t, err) {
+			b, err = enc.Flush(b)
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		in = b
+		if strings.HasSuffix(line, "\n") {
+			in = append(in, '\n')
+		}
+	}
+	dec := NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+	dec.ControlPassthrough = true
+	for _, chunkSize := range []int{1, 7, 64, 0} {
+		result, err := DecodeParallel(context.Background(), dec, in, ParallelOptions{ChunkSize: chunkSize})
+		if assert.NoError(t, err) {
+			assert.Equal(t, input, string(result))
+		}
+	}
+
+	_, err := DecodeParallel(context.Background(), dec, []byte{0x30, 0x21, 0x30, 0x21, 0x30}, ParallelOptions{ChunkSize: 2})
+	assert.EqualError(t, err, "incomplete character at offset 4")
+
+	// the offsets in the errors are the same as those of Decode
+	in = []byte{0x30, 0x21, 0x30, 0x21, 0x30, 0x21, 0x22, 0x31}
+	_, expected := NewJNTAJISDecoder(ConversionModeSISO, InvalidRune).Decode(nil, in)
+	if assert.Error(t, expected) {
+		_, err = DecodeParallel(context.Background(), dec, in, ParallelOptions{ChunkSize: 2})
+		assert.Equal(t, expected, err)
+	}
+
+	// a reserved cell at the end of the first chunk and a bad byte at the
+	// start of the second one
+	in = append(bytes.Repeat([]byte{0x30, 0x21}, 200000), 0x22, 0x31, 0x0a, 0x30, 0x21)
+	_, expected = NewJNTAJISDecoder(ConversionModeSISO, InvalidRune).Decode(nil, in)
+	assert.EqualError(t, expected, "inconvertible character found at offset 400000")
+	for i := 0; i < 10; i++ {
+		_, err = DecodeParallel(context.Background(), NewJNTAJISDecoder(ConversionModeSISO, InvalidRune), in, ParallelOptions{ChunkSize: 400002, Workers: 4})
+		assert.Equal(t, expected, err)
+	}
+}
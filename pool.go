@@ -0,0 +1,65 @@
+package jntajis
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	encoderPools [ConversionModeTranslit + 1]sync.Pool
+	decoderPools [ConversionModeTranslit + 1]sync.Pool
+)
+
+func init() {
+	for i := range encoderPools {
+		mode := ConversionMode(i)
+		encoderPools[i].New = func() interface{} {
+			return NewJNTAJISIncrementalEncoder(mode, InvalidJISCode)
+		}
+		decoderPools[i].New = func() interface{} {
+			return NewJNTAJISDecoder(mode, InvalidRune)
+		}
+	}
+}
+
+func checkMode(mode ConversionMode) {
+	if mode < ConversionModeSISO || mode > ConversionModeTranslit {
+		panic(fmt.Sprintf("unknown mode: %s", mode))
+	}
+}
+
+// Encode converts s in mode, failing on characters that are not convertible.
+// It is safe for concurrent use.
+func Encode(mode ConversionMode, s string) ([]byte, error) {
+	checkMode(mode)
+	p := &encoderPools[mode]
+	e := p.Get().(*JNTAJISIncrementalEncoder)
+	defer p.Put(e)
+	e.Reset()
+	b, err := e.Encode(make([]byte, 0, len(s)), s)
+	if err == nil {
+		b, err = e.Flush(b)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Decode converts b encoded in mode, failing on reserved cells and on an
+// incomplete character at the end. It is safe for concurrent use.
+func Decode(mode ConversionMode, b []byte) (string, error) {
+	checkMode(mode)
+	p := &decoderPools[mode]
+	d := p.Get().(*JNTAJISDecoder)
+	defer p.Put(d)
+	d.Reset()
+	s, err := d.Decode(make([]byte, 0, len(b)*2), b)
+	if err == nil && d.upper > 0 {
+		err = fmt.Errorf("incomplete character at offset %d", len(b)-1)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(s), nil
+}
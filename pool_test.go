@@ -0,0 +1,102 @@
+package jntajis
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageEncodeDecode(t *testing.T) {
+	b, err := Encode(ConversionModeSISO, "山\U00020089か")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x3b, 0x33, 0x0f, 0x21, 0x21, 0x0e, 0x24, 0x2b}, b)
+		s, err := Decode(ConversionModeSISO, b)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "山\U00020089か", s)
+		}
+	}
+	_, err = Encode(ConversionModeJISX0208, "\U00020089")
+	assert.EqualError(t, err, "\U00020089 is not convertible to JISX0208")
+	_, err = Decode(ConversionModeMen1, []byte{0x30, 0x21, 0x30})
+	assert.EqualError(t, err, "incomplete character at offset 2")
+
+	// a failed call leaves nothing behind for the next one
+	_, err = Decode(ConversionModeSISO, []byte{0x0f, 0x21})
+	assert.Error(t, err)
+	s, err := Decode(ConversionModeSISO, []byte{0x30, 0x21})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "亜", s)
+	}
+}
+
+// The tests below are meant to be run with the race detector.
+
+func TestPackageEncodeDecodeConcurrently(t *testing.T) {
+	inputs := []string{"山\U00020089か゚", "ジャンクロードヴァンダム", "亜か", "\U00020089\U00020089"}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				input := inputs[(g+i)%len(inputs)]
+				b, err := Encode(ConversionModeSISO, input)
+				if !assert.NoError(t, err) {
+					return
+				}
+				s, err := Decode(ConversionModeSISO, b)
+				if !assert.NoError(t, err) || !assert.Equal(t, input, s) {
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestSharedEncoderConcurrently(t *testing.T) {
+	enc := NewJNTAJISEncoder(ConversionModeTranslit, InvalidJISCode)
+	o := NewOverrides()
+	assert.NoError(t, o.SetTranslit('﨑', MenKuTen{1, 26, 68}.JIS()))
+	enc.Overrides = o
+	expected, err := enc.EncodeAsJISX0213Men1("﨑繫ジャンク")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				b, err := enc.EncodeAsJISX0213Men1("﨑繫ジャンク")
+				if !assert.NoError(t, err) || !assert.Equal(t, expected, b) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPackageEncodeAllModesConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	for _, mode := range []ConversionMode{ConversionModeSISO, ConversionModeMen1, ConversionModeJISX0208, ConversionModeTranslit} {
+		expected, err := Encode(mode, "亜繫")
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func(mode ConversionMode) {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					b, err2 := Encode(mode, "亜繫")
+					if !assert.Equal(t, fmt.Sprint(err), fmt.Sprint(err2)) || !assert.Equal(t, expected, b) {
+						return
+					}
+				}
+			}(mode)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,34 @@
+package jntajis
+
+// ReservedPUABase is the first code point of the range reserved cells are
+// decoded to when ReservedAsPUA is set. The cell with the packed men-ku-ten
+// code c corresponds to ReservedPUABase+c, which lies in the Supplementary
+// Private Use Area-A and thus never collides with gaiji in the BMP.
+const ReservedPUABase = rune(0xf0000)
+
+func reservedPUA(jis uint32) rune {
+	return ReservedPUABase + rune(jis)
+}
+
+func jisForReservedPUA(r rune) (uint32, bool) {
+	if r < ReservedPUABase || r >= ReservedPUABase+rune(len(txMappings())) {
+		return InvalidJISCode, false
+	}
+	jis := uint32(r - ReservedPUABase)
+	if txMappings()[jis].class != Reserved {
+		return InvalidJISCode, false
+	}
+	return jis, true
+}
+
+// putReserved emits a reserved cell as is. Such cells only appear on explicit
+// request of the user, so JISX0208 and transliteration modes accept them too
+// as long as they are in the first plane.
+func putReserved(b []byte, jis uint32, mode ConversionMode, put func([]byte, uint32) ([]byte, bool)) ([]byte, bool) {
+	switch mode {
+	case ConversionModeJISX0208, ConversionModeTranslit:
+		return putJISMen1(b, jis)
+	default:
+		return put(b, jis)
+	}
+}
@@ -0,0 +1,75 @@
+package jntajis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservedAsPUARoundTrip(t *testing.T) {
+	var in []byte
+	plane := uint32(0)
+	for i, m := range txMappings() {
+		if m.class != Reserved {
+			continue
+		}
+		// trailing entries are left zero-valued in the table
+		jis := uint32(i)
+		if jis/(94*94) != plane {
+			plane = jis / (94 * 94)
+			in = append(in, 0x0e+byte(plane))
+		}
+		in = append(in, byte(0x21+(jis/94)%94), byte(0x21+jis%94))
+	}
+	if plane != 0 {
+		in = append(in, 0x0e)
+	}
+
+	dec := NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+	dec.ReservedAsPUA = true
+	decoded, err := dec.Decode(nil, in)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, r := range string(decoded) {
+		if !assert.True(t, r >= ReservedPUABase && r < ReservedPUABase+2*94*94) {
+			return
+		}
+	}
+
+	enc := NewJNTAJISIncrementalEncoder(ConversionModeSISO, InvalidJISCode)
+	enc.ReservedAsPUA = true
+	encoded, err := enc.Encode(nil, string(decoded))
+	if assert.NoError(t, err) {
+		encoded, err = enc.Flush(encoded)
+		if assert.NoError(t, err) {
+			assert.Equal(t, in, encoded)
+		}
+	}
+}
+
+func TestReservedAsPUA(t *testing.T) {
+	g := NewGaijiRegistry()
+	assert.NoError(t, g.Register(0xe000, MenKuTen{1, 2, 17}.JIS()))
+
+	dec := NewJNTAJISDecoder(ConversionModeMen1, InvalidRune)
+	dec.Gaiji = g
+	dec.ReservedAsPUA = true
+	result, err := dec.Decode(nil, []byte{0x22, 0x31, 0x22, 0x32, 0x21, 0x21})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "\U000f006f　", string(result))
+	}
+
+	enc := NewJNTAJISEncoder(ConversionModeJISX0208, InvalidJISCode)
+	enc.ReservedAsPUA = true
+	encoded, err := enc.EncodeAsJISX0213Men1("\U000f006f")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x22, 0x32}, encoded)
+	}
+	_, err = enc.EncodeAsJISX0213Men1("\U000f0000")
+	assert.EqualError(t, err, "\U000f0000 is not convertible to JISX0208")
+
+	enc = NewJNTAJISEncoder(ConversionModeMen1, InvalidJISCode)
+	_, err = enc.EncodeAsJISX0213Men1("\U000f006f")
+	assert.EqualError(t, err, "\U000f006f is not convertible to JISX0208")
+}
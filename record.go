@@ -0,0 +1,270 @@
+package jntajis
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RecordPadding specifies how a field of a fixed-length record is filled up.
+type RecordPadding int
+
+const (
+	// fill with JIS X 0208 ideographic spaces (0x2121)
+	RecordPaddingSpace = RecordPadding(iota)
+	// the encoded value must fill the field exactly
+	RecordPaddingNone
+)
+
+type recordField struct {
+	name    string
+	index   int
+	offset  int
+	length  int
+	padding RecordPadding
+	mode    ConversionMode
+}
+
+// RecordCodec converts a struct to and from a fixed-length record of JIS
+// bytes. Each string field of the struct is laid out as specified by its
+// "jis" tag, for example:
+//
+//	Name string `jis:"offset=0,len=40,pad=space,mode=translit"`
+//
+// where pad is either space (the default) or none, and mode is one of siso,
+// men1, jisx0208 (the default) and translit. The shift state is reset for
+// every field in siso mode. Bytes that are not covered by any field are left
+// zero.
+type RecordCodec struct {
+	typ    reflect.Type
+	fields []recordField
+	size   int
+}
+
+func parseRecordMode(v string) (ConversionMode, error) {
+	switch v {
+	case "siso":
+		return ConversionModeSISO, nil
+	case "men1":
+		return ConversionModeMen1, nil
+	case "jisx0208":
+		return ConversionModeJISX0208, nil
+	case "translit":
+		return ConversionModeTranslit, nil
+	default:
+		return 0, fmt.Errorf("unknown mode: %s", v)
+	}
+}
+
+func parseRecordFieldTag(f *recordField, tag string) error {
+	f.offset, f.length = -1, -1
+	f.mode = ConversionModeJISX0208
+	for _, kv := range strings.Split(tag, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		i := strings.IndexByte(kv, '=')
+		if i < 0 {
+			return fmt.Errorf("invalid tag element: %s", kv)
+		}
+		k, v := kv[:i], kv[i+1:]
+		var err error
+		switch k {
+		case "offset":
+			f.offset, err = strconv.Atoi(v)
+		case "len":
+			f.length, err = strconv.Atoi(v)
+		case "pad":
+			switch v {
+			case "space":
+				f.padding = RecordPaddingSpace
+			case "none":
+				f.padding = RecordPaddingNone
+			default:
+				err = fmt.Errorf("unknown padding: %s", v)
+			}
+		case "mode":
+			f.mode, err = parseRecordMode(v)
+		default:
+			err = fmt.Errorf("unknown tag element: %s", k)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if f.offset < 0 {
+		return fmt.Errorf("offset is not specified")
+	}
+	if f.length <= 0 {
+		return fmt.Errorf("len is not specified")
+	}
+	return nil
+}
+
+func structTypeOf(v interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%T is not a struct", v)
+	}
+	return t, nil
+}
+
+// NewRecordCodec builds a codec for the type of v, which is either a struct
+// or a pointer to a struct.
+func NewRecordCodec(v interface{}) (*RecordCodec, error) {
+	t, err := structTypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+	c := &RecordCodec{typ: t}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("jis")
+		if !ok || tag == "-" {
+			continue
+		}
+		if sf.PkgPath != "" {
+			return nil, fmt.Errorf("field %s: unexported", sf.Name)
+		}
+		if sf.Type.Kind() != reflect.String {
+			return nil, fmt.Errorf("field %s: unsupported type %s", sf.Name, sf.Type)
+		}
+		f := recordField{name: sf.Name, index: i}
+		if err := parseRecordFieldTag(&f, tag); err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		c.fields = append(c.fields, f)
+	}
+	sort.Slice(c.fields, func(i, j int) bool {
+		return c.fields[i].offset < c.fields[j].offset
+	})
+	for i, f := range c.fields {
+		if i > 0 && f.offset < c.size {
+			return nil, fmt.Errorf("field %s overlaps with %s", f.name, c.fields[i-1].name)
+		}
+		c.size = f.offset + f.length
+	}
+	return c, nil
+}
+
+// Len returns the length of a record in bytes.
+func (c *RecordCodec) Len() int {
+	return c.size
+}
+
+func (c *RecordCodec) structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || rv.Type() != c.typ {
+		return reflect.Value{}, fmt.Errorf("%T does not match %s", v, c.typ)
+	}
+	return rv, nil
+}
+
+func encodeRecordField(b []byte, f *recordField, s string) ([]byte, error) {
+	if f.mode == ConversionModeSISO {
+		enc := NewJNTAJISIncrementalEncoder(f.mode, InvalidJISCode)
+		b, err := enc.Encode(b, s)
+		if err != nil {
+			return b, err
+		}
+		return enc.Flush(b)
+	}
+	return NewJNTAJISEncoder(f.mode, InvalidJISCode).encode(b, s, nil)
+}
+
+// Marshal converts v, which is either a struct or a pointer to a struct of
+// the type the codec was built for, to a record.
+func (c *RecordCodec) Marshal(v interface{}) ([]byte, error) {
+	rv, err := c.structValue(v)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, c.size)
+	for i := range c.fields {
+		f := &c.fields[i]
+		fb, err := encodeRecordField(b[f.offset:f.offset], f, rv.Field(f.index).String())
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.name, err)
+		}
+		if len(fb) > f.length {
+			return nil, fmt.Errorf("field %s: encoded value is %d bytes long, which exceeds %d", f.name, len(fb), f.length)
+		}
+		copy(b[f.offset:], fb)
+		r := f.length - len(fb)
+		switch f.padding {
+		case RecordPaddingSpace:
+			if r%2 != 0 {
+				return nil, fmt.Errorf("field %s: %d bytes cannot be filled with spaces", f.name, r)
+			}
+			for j := f.offset + len(fb); j < f.offset+f.length; j++ {
+				b[j] = 0x21
+			}
+		case RecordPaddingNone:
+			if r != 0 {
+				return nil, fmt.Errorf("field %s: encoded value is %d bytes long, which does not fill %d", f.name, len(fb), f.length)
+			}
+		}
+	}
+	return b, nil
+}
+
+// Unmarshal converts a record to v, which must be a pointer to a struct of
+// the type the codec was built for. Trailing ideographic spaces are removed
+// from the fields padded with spaces.
+func (c *RecordCodec) Unmarshal(b []byte, v interface{}) error {
+	if reflect.ValueOf(v).Kind() != reflect.Ptr {
+		return fmt.Errorf("%T is not a pointer", v)
+	}
+	rv, err := c.structValue(v)
+	if err != nil {
+		return err
+	}
+	if len(b) < c.size {
+		return fmt.Errorf("record is %d bytes long, which is shorter than %d", len(b), c.size)
+	}
+	for i := range c.fields {
+		f := &c.fields[i]
+		dec := NewJNTAJISDecoder(f.mode, InvalidRune)
+		s, err := dec.Decode(nil, b[f.offset:f.offset+f.length])
+		if err == nil && dec.upper > 0 {
+			err = fmt.Errorf("incomplete character at offset %d", f.length-1)
+		}
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.name, err)
+		}
+		value := string(s)
+		if f.padding == RecordPaddingSpace {
+			value = strings.TrimRight(value, "　")
+		}
+		rv.Field(f.index).SetString(value)
+	}
+	return nil
+}
+
+// MarshalRecord converts v to a record with a RecordCodec built for its type.
+func MarshalRecord(v interface{}) ([]byte, error) {
+	c, err := NewRecordCodec(v)
+	if err != nil {
+		return nil, err
+	}
+	return c.Marshal(v)
+}
+
+// UnmarshalRecord converts a record to v with a RecordCodec built for its
+// type.
+func UnmarshalRecord(b []byte, v interface{}) error {
+	c, err := NewRecordCodec(v)
+	if err != nil {
+		return err
+	}
+	return c.Unmarshal(b, v)
+}
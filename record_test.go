@@ -0,0 +1,85 @@
+package jntajis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecord struct {
+	Name    string `jis:"offset=0,len=6,mode=translit"`
+	Kana    string `jis:"offset=6,len=4,pad=none"`
+	Note    string `jis:"offset=12,len=8,mode=siso"`
+	Ignored string
+}
+
+func TestRecordCodec(t *testing.T) {
+	c, err := NewRecordCodec(testRecord{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 20, c.Len())
+
+	b, err := c.Marshal(&testRecord{Name: "繫", Kana: "アイ", Note: "\U00020089", Ignored: "x"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []byte{
+		0x37, 0x52, 0x21, 0x21, 0x21, 0x21,
+		0x25, 0x22, 0x25, 0x24,
+		0x00, 0x00,
+	}, b[:12])
+	assert.Equal(t, byte(0x0f), b[12])
+
+	var r testRecord
+	if assert.NoError(t, c.Unmarshal(b, &r)) {
+		assert.Equal(t, testRecord{Name: "繋", Kana: "アイ", Note: "\U00020089"}, r)
+	}
+}
+
+func TestRecordCodecErrors(t *testing.T) {
+	c, err := NewRecordCodec(&testRecord{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = c.Marshal(testRecord{Name: "亜亜亜亜", Kana: "アイ"})
+	assert.EqualError(t, err, "field Name: encoded value is 8 bytes long, which exceeds 6")
+	_, err = c.Marshal(testRecord{Kana: "ア"})
+	assert.EqualError(t, err, "field Kana: encoded value is 2 bytes long, which does not fill 4")
+	_, err = c.Marshal(struct{}{})
+	assert.EqualError(t, err, "struct {} does not match jntajis.testRecord")
+	assert.EqualError(t, c.Unmarshal(make([]byte, 10), &testRecord{}), "record is 10 bytes long, which is shorter than 20")
+	assert.EqualError(t, c.Unmarshal(make([]byte, 20), testRecord{}), "jntajis.testRecord is not a pointer")
+
+	_, err = NewRecordCodec(struct {
+		A string `jis:"offset=0,len=4"`
+		B string `jis:"offset=2,len=4"`
+	}{})
+	assert.EqualError(t, err, "field B overlaps with A")
+	_, err = NewRecordCodec(struct {
+		A string `jis:"offset=0,len=4,pad=zero"`
+	}{})
+	assert.EqualError(t, err, "field A: unknown padding: zero")
+	_, err = NewRecordCodec(struct {
+		A int `jis:"offset=0,len=4"`
+	}{})
+	assert.EqualError(t, err, "field A: unsupported type int")
+	_, err = NewRecordCodec(struct {
+		a string `jis:"offset=0,len=4"`
+	}{})
+	assert.EqualError(t, err, "field a: unexported")
+}
+
+func TestMarshalRecord(t *testing.T) {
+	type rec struct {
+		A string `jis:"offset=0,len=4,mode=men1"`
+	}
+	b, err := MarshalRecord(rec{A: "亜"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x30, 0x21, 0x21, 0x21}, b)
+		var r rec
+		if assert.NoError(t, UnmarshalRecord(b, &r)) {
+			assert.Equal(t, "亜", r.A)
+		}
+	}
+}
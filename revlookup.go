@@ -0,0 +1,136 @@
+package jntajis
+
+// revTrie maps a rune to a packed men-ku-ten code. The BMP goes through a
+// two-level table of 256-rune blocks where identical blocks (typically the
+// empty ones) are shared; the rest lives in a map.
+type revTrie struct {
+	bmpIndex  [256]uint16
+	bmpBlocks [][256]uint32
+	supp      map[rune]uint32
+}
+
+func (t *revTrie) lookup(r rune) (uint32, bool) {
+	var jis uint32
+	if r >= 0 && r < 0x10000 {
+		jis = t.bmpBlocks[t.bmpIndex[r>>8]][r&0xff]
+	} else {
+		var ok bool
+		jis, ok = t.supp[r]
+		if !ok {
+			return 0, false
+		}
+	}
+	if jis == InvalidJISCode {
+		return 0, false
+	}
+	return jis, true
+}
+
+func buildRevTrie(ranges []runeRangeToJISMapping) *revTrie {
+	var blocks [256][256]uint32
+	for i := range blocks {
+		for j := range blocks[i] {
+			blocks[i][j] = InvalidJISCode
+		}
+	}
+	t := &revTrie{supp: make(map[rune]uint32)}
+	for _, rm := range ranges {
+		for i, jis := range rm.jis {
+			if jis == InvalidJISCode {
+				continue
+			}
+			r := rm.start + rune(i)
+			if r < 0x10000 {
+				blocks[r>>8][r&0xff] = jis
+			} else {
+				t.supp[r] = jis
+			}
+		}
+	}
+	seen := make(map[[256]uint32]uint16)
+	for i := range blocks {
+		j, ok := seen[blocks[i]]
+		if !ok {
+			j = uint16(len(t.bmpBlocks))
+			t.bmpBlocks = append(t.bmpBlocks, blocks[i])
+			seen[blocks[i]] = j
+		}
+		t.bmpIndex[i] = j
+	}
+	return t
+}
+
+type runePairMapping struct {
+	second rune
+	jis    uint32
+}
+
+// runePairStarter is the first rune of combining pairs. The state of
+// smRuneToJISMapping after the i-th starter is i+1.
+type runePairStarter struct {
+	first rune
+	pairs []runePairMapping
+}
+
+// buildRunePairStarters collects the cells made of two runes, ordering the
+// starters by rune.
+func buildRunePairStarters(mappings *[2 * 94 * 94]shrinkingTransliterationMapping) []runePairStarter {
+	var starters []runePairStarter
+	for i := range mappings {
+		m := &mappings[i]
+		if m.class == Reserved || m.rs[1] == InvalidRune {
+			continue
+		}
+		k := searchRunePairStarters(starters, m.rs[0])
+		if k == len(starters) || starters[k].first != m.rs[0] {
+			starters = append(starters, runePairStarter{})
+			copy(starters[k+1:], starters[k:])
+			starters[k] = runePairStarter{first: m.rs[0]}
+		}
+		starters[k].pairs = append(starters[k].pairs, runePairMapping{second: m.rs[1], jis: uint32(i)})
+	}
+	return starters
+}
+
+func searchRunePairStarters(starters []runePairStarter, r rune) int {
+	s, e := 0, len(starters)
+	for s < e {
+		m := (s + e) / 2
+		if starters[m].first < r {
+			s = m + 1
+		} else {
+			e = m
+		}
+	}
+	return s
+}
+
+func lookupRevTable(r rune) (uint32, bool) {
+	tableOnce.Do(loadTables)
+	return revTrieTable.lookup(r)
+}
+
+// smRuneToJISMapping advances the state machine that recognizes combining
+// pairs. State 0 is the initial state, a positive state follows the first
+// rune of a pair, and -1 tells that r completed the pair returned.
+func smRuneToJISMapping(state int, r rune) (int, uint32) {
+	tableOnce.Do(loadTables)
+	starters := runePairStartersTable
+	if state < 0 || state > len(starters) {
+		return state, 0
+	}
+	if state > 0 {
+		for _, p := range starters[state-1].pairs {
+			if p.second == r {
+				return -1, p.jis
+			}
+		}
+	}
+	// r may start another pair
+	if len(starters) > 0 && r >= starters[0].first && r <= starters[len(starters)-1].first {
+		if k := searchRunePairStarters(starters, r); starters[k].first == r {
+			return k + 1, 0
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,300 @@
+package jntajis
+
+import "testing"
+
+// smRuneToJISMappingSwitch is the switch that gen.go used to generate in
+// place of the table-driven smRuneToJISMapping, kept as the reference.
+func smRuneToJISMappingSwitch(state int, r rune) (int, uint32) {
+	var final uint32
+reenter:
+	switch state {
+	case 0:
+		if r < 230 || r > 12791 {
+			break
+		}
+		switch r {
+		case 230:
+			state = 1
+		case 596:
+			state = 2
+		case 601:
+			state = 3
+		case 602:
+			state = 4
+		case 652:
+			state = 5
+		case 741:
+			state = 6
+		case 745:
+			state = 7
+		case 12363:
+			state = 8
+		case 12365:
+			state = 9
+		case 12367:
+			state = 10
+		case 12369:
+			state = 11
+		case 12371:
+			state = 12
+		case 12459:
+			state = 13
+		case 12461:
+			state = 14
+		case 12463:
+			state = 15
+		case 12465:
+			state = 16
+		case 12467:
+			state = 17
+		case 12475:
+			state = 18
+		case 12484:
+			state = 19
+		case 12488:
+			state = 20
+		case 12791:
+			state = 21
+		}
+	case 1:
+		switch r {
+		case 768:
+			final = 975
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 2:
+		switch r {
+		case 769:
+			final = 980
+			state = -1
+		case 768:
+			final = 979
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 3:
+		switch r {
+		case 769:
+			final = 984
+			state = -1
+		case 768:
+			final = 983
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 4:
+		switch r {
+		case 768:
+			final = 985
+			state = -1
+		case 769:
+			final = 986
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 5:
+		switch r {
+		case 769:
+			final = 982
+			state = -1
+		case 768:
+			final = 981
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 6:
+		switch r {
+		case 745:
+			final = 1009
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 7:
+		switch r {
+		case 741:
+			final = 1008
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 8:
+		switch r {
+		case 12442:
+			final = 368
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 9:
+		switch r {
+		case 12442:
+			final = 369
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 10:
+		switch r {
+		case 12442:
+			final = 370
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 11:
+		switch r {
+		case 12442:
+			final = 371
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 12:
+		switch r {
+		case 12442:
+			final = 372
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 13:
+		switch r {
+		case 12442:
+			final = 462
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 14:
+		switch r {
+		case 12442:
+			final = 463
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 15:
+		switch r {
+		case 12442:
+			final = 464
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 16:
+		switch r {
+		case 12442:
+			final = 465
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 17:
+		switch r {
+		case 12442:
+			final = 466
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 18:
+		switch r {
+		case 12442:
+			final = 467
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 19:
+		switch r {
+		case 12442:
+			final = 468
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 20:
+		switch r {
+		case 12442:
+			final = 469
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	case 21:
+		switch r {
+		case 12442:
+			final = 557
+			state = -1
+		default:
+			state = 0
+			goto reenter
+		}
+	}
+	return state, final
+}
+
+func TestSmRuneToJISMappingAgreesWithSwitch(t *testing.T) {
+	tableOnce.Do(loadTables)
+	for state := 0; state <= len(runePairStartersTable); state++ {
+		for r := rune(0); r < 0x10000; r++ {
+			es, ejis := smRuneToJISMappingSwitch(state, r)
+			s, jis := smRuneToJISMapping(state, r)
+			if es != s || ejis != jis {
+				t.Fatalf("state %d, %U: expected (%d, %d), got (%d, %d)", state, r, es, ejis, s, jis)
+			}
+		}
+	}
+}
+
+func benchmarkSmRuneToJISMappingSwitch(b *testing.B, rs []rune) {
+	b.SetBytes(int64(len(string(rs))))
+	for i := 0; i < b.N; i++ {
+		state := 0
+		for _, r := range rs {
+			state, _ = smRuneToJISMappingSwitch(state, r)
+			if state < 0 {
+				state = 0
+			}
+		}
+	}
+}
+
+func BenchmarkSmRuneToJISMappingSwitchKanji(b *testing.B) {
+	benchmarkSmRuneToJISMappingSwitch(b, benchRevKanji)
+}
+
+func BenchmarkSmRuneToJISMappingSwitchKana(b *testing.B) {
+	benchmarkSmRuneToJISMappingSwitch(b, benchRevKana)
+}
+
+func BenchmarkSmRuneToJISMappingSwitchASCII(b *testing.B) {
+	benchmarkSmRuneToJISMappingSwitch(b, benchRevASCII)
+}
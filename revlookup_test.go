@@ -0,0 +1,165 @@
+package jntajis
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testRuneRangesOnce sync.Once
+	testRuneRanges     []runeRangeToJISMapping
+)
+
+// runeRangeToJISMappings returns the rune ranges in table.bin, which the
+// package itself discards once the trie is built.
+func runeRangeToJISMappings() []runeRangeToJISMapping {
+	testRuneRangesOnce.Do(func() {
+		var m [2 * 94 * 94]shrinkingTransliterationMapping
+		ranges, err := decodeTables(tableBlob, &m)
+		if err != nil {
+			panic(err)
+		}
+		testRuneRanges = ranges
+	})
+	return testRuneRanges
+}
+
+// searchRuneRanges is the binary search over the rune ranges that the trie
+// replaced, kept as the reference.
+func searchRuneRanges(ranges []runeRangeToJISMapping, r rune) (uint32, bool) {
+	s, e := 0, len(ranges)
+	for s < e {
+		m := (s + e) / 2
+		mm := &ranges[m]
+		if r < mm.start {
+			e = m
+			continue
+		} else if r > mm.end {
+			s = m + 1
+			continue
+		}
+		o := int(r - mm.start)
+		if o >= len(mm.jis) {
+			return 0, false
+		}
+		jis := mm.jis[o]
+		if jis == InvalidJISCode {
+			return 0, false
+		}
+		return jis, true
+	}
+	return 0, false
+}
+
+func TestLookupRevTableAgreesWithBinarySearch(t *testing.T) {
+	ranges := runeRangeToJISMappings()
+	for r := rune(-1); r <= 0x110000; r++ {
+		expected, expectedOk := searchRuneRanges(ranges, r)
+		jis, ok := lookupRevTable(r)
+		if !assert.Equal(t, expectedOk, ok, "%U", r) || !assert.Equal(t, expected, jis, "%U", r) {
+			return
+		}
+	}
+}
+
+func TestSmRuneToJISMapping(t *testing.T) {
+	n := 0
+	for i, m := range txMappings() {
+		if m.class == Reserved || m.rs[1] == InvalidRune {
+			continue
+		}
+		n++
+		state, _ := smRuneToJISMapping(0, m.rs[0])
+		if !assert.Greater(t, state, 0, "%U", m.rs[0]) {
+			continue
+		}
+		s, jis := smRuneToJISMapping(state, m.rs[1])
+		assert.Equal(t, -1, s)
+		assert.Equal(t, uint32(i), jis)
+		s, _ = smRuneToJISMapping(state, 'a')
+		assert.Equal(t, 0, s)
+		// an unmatched rune may start another pair
+		s, _ = smRuneToJISMapping(state, m.rs[0])
+		assert.Equal(t, state, s)
+	}
+	assert.Equal(t, 25, n)
+	s, jis := smRuneToJISMapping(0, '亜')
+	assert.Equal(t, 0, s)
+	assert.Equal(t, uint32(0), jis)
+}
+
+var (
+	benchRevKanji = []rune(strings.Repeat("国税庁法人番号公表高橋﨑山繫體龢\U00020089", 64))
+	benchRevKana  = []rune(strings.Repeat("ジャンクロードヴァンダムかか゚ゔゕゖ", 64))
+	benchRevASCII = []rune(strings.Repeat("ABC Corporation 123 Ltd. ", 64))
+)
+
+func benchmarkLookup(b *testing.B, rs []rune, lookup func(rune) (uint32, bool)) {
+	lookup(0)
+	b.SetBytes(int64(len(string(rs))))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, r := range rs {
+			lookup(r)
+		}
+	}
+}
+
+func BenchmarkLookupRevTableKanji(b *testing.B) {
+	benchmarkLookup(b, benchRevKanji, lookupRevTable)
+}
+
+func BenchmarkLookupRevTableKana(b *testing.B) {
+	benchmarkLookup(b, benchRevKana, lookupRevTable)
+}
+
+func BenchmarkLookupRevTableASCII(b *testing.B) {
+	benchmarkLookup(b, benchRevASCII, lookupRevTable)
+}
+
+func lookupRevTableBinarySearch(r rune) (uint32, bool) {
+	return searchRuneRanges(testRuneRanges, r)
+}
+
+func BenchmarkLookupRevTableBinarySearchKanji(b *testing.B) {
+	runeRangeToJISMappings()
+	benchmarkLookup(b, benchRevKanji, lookupRevTableBinarySearch)
+}
+
+func BenchmarkLookupRevTableBinarySearchKana(b *testing.B) {
+	runeRangeToJISMappings()
+	benchmarkLookup(b, benchRevKana, lookupRevTableBinarySearch)
+}
+
+func BenchmarkLookupRevTableBinarySearchASCII(b *testing.B) {
+	runeRangeToJISMappings()
+	benchmarkLookup(b, benchRevASCII, lookupRevTableBinarySearch)
+}
+
+func benchmarkSmRuneToJISMapping(b *testing.B, rs []rune) {
+	b.SetBytes(int64(len(string(rs))))
+	for i := 0; i < b.N; i++ {
+		state := 0
+		for _, r := range rs {
+			state, _ = smRuneToJISMapping(state, r)
+			if state < 0 {
+				state = 0
+			}
+		}
+	}
+}
+
+func BenchmarkSmRuneToJISMappingKanji(b *testing.B) {
+	benchmarkSmRuneToJISMapping(b, benchRevKanji)
+}
+
+func BenchmarkSmRuneToJISMappingKana(b *testing.B) {
+	benchmarkSmRuneToJISMapping(b, benchRevKana)
+}
+
+func BenchmarkSmRuneToJISMappingASCII(b *testing.B) {
+	benchmarkSmRuneToJISMapping(b, benchRevASCII)
+}
@@ -0,0 +1,79 @@
+package jntajis
+
+import "unicode/utf8"
+
+// segment is a unit of the input that corresponds to a single JIS character,
+// which is either a single rune or a combining pair recognized by
+// smRuneToJISMapping.
+type segment struct {
+	// byte offset in the input
+	offset int
+	// rune index in the input
+	index int
+	// length in bytes
+	size int
+	// the first rune; also the only one unless pair is set
+	r rune
+	// the last rune
+	last rune
+	pair bool
+	// packed men-ku-ten code of the pair; meaningful only if pair is set
+	jis uint32
+}
+
+func (seg *segment) nrunes() int {
+	if seg.pair {
+		return 2
+	}
+	return 1
+}
+
+// forEachSegment splits s into segments with the same lookahead logic as the
+// encoders and calls f for each of them. It stops at the first error f
+// returns.
+func forEachSegment(s string, f func(seg segment) error) error {
+	var pending segment
+	hasPending := false
+	state := 0
+	index := 0
+	size := 0
+	for offset := 0; offset < len(s); offset += size {
+		var r rune
+		r, size = utf8.DecodeRuneInString(s[offset:])
+		var jis uint32
+		state, jis = smRuneToJISMapping(state, r)
+		if hasPending {
+			if state == -1 {
+				pending.size = offset + size - pending.offset
+				pending.last = r
+				pending.pair = true
+				pending.jis = jis
+				hasPending = false
+				state = 0
+				index++
+				if err := f(pending); err != nil {
+					return err
+				}
+				continue
+			}
+			hasPending = false
+			if err := f(pending); err != nil {
+				return err
+			}
+		}
+		seg := segment{offset: offset, index: index, size: size, r: r, last: r}
+		index++
+		if state != 0 {
+			pending = seg
+			hasPending = true
+			continue
+		}
+		if err := f(seg); err != nil {
+			return err
+		}
+	}
+	if hasPending {
+		return f(pending)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package jntajis
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+)
+
+// JISSplitter provides bufio.SplitFuncs for JIS byte streams. In SISO mode
+// it follows SO and SI across tokens as JNTAJISDecoder does.
+type JISSplitter struct {
+	// ControlPassthrough makes ScanCharacters yield the control characters
+	// as single-byte tokens, as JNTAJISDecoder does with the same option.
+	ControlPassthrough bool
+	siso               bool
+	shiftOffset        int
+	men                int
+	offset             int
+}
+
+// Men returns the plane in effect at the start of the last token.
+func (s *JISSplitter) Men() int {
+	return s.men
+}
+
+func (s *JISSplitter) startToken() {
+	s.men = s.shiftOffset/(94*94) + 1
+}
+
+// ScanCharacters yields a two-byte token for every character. SO and SI are
+// consumed without yielding tokens.
+func (s *JISSplitter) ScanCharacters(data []byte, atEOF bool) (int, []byte, error) {
+	shiftOffset := s.shiftOffset
+	i := 0
+	for ; i < len(data); i++ {
+		if c0 := data[i]; s.siso && c0 == 0x0e {
+			shiftOffset = 0
+		} else if s.siso && c0 == 0x0f {
+			shiftOffset = 94 * 94
+		} else {
+			break
+		}
+	}
+	commit := func(n int) {
+		s.shiftOffset = shiftOffset
+		s.offset += n
+	}
+	if i >= len(data) {
+		// only shift bytes are available for now
+		commit(i)
+		return i, nil, nil
+	}
+	o := s.offset + i
+	c0 := int(data[i])
+	switch {
+	case c0 >= 0x21 && c0 <= 0x7e:
+		if i+1 >= len(data) {
+			if atEOF {
+				return 0, nil, fmt.Errorf("incomplete character at offset %d", o)
+			}
+			commit(i)
+			return i, nil, nil
+		}
+		if c1 := data[i+1]; c1 < 0x21 || c1 > 0x7e {
+			return 0, nil, fmt.Errorf("unexpected byte \\x%02x after \\x%02x at offset %d", c1, c0, o)
+		}
+		commit(i + 2)
+		s.startToken()
+		return i + 2, data[i : i+2], nil
+	case s.ControlPassthrough && isControlByte(c0, s.siso):
+		commit(i + 1)
+		s.startToken()
+		return i + 1, data[i : i+1], nil
+	default:
+		return 0, nil, fmt.Errorf("unexpected byte \\x%02x at offset %d", c0, o)
+	}
+}
+
+// ScanLines yields every line without the trailing LF or CR LF, for the
+// streams that are decoded with ControlPassthrough. The shift state at the
+// start of the line is reported by Men.
+func (s *JISSplitter) ScanLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	n := bytes.IndexByte(data, '\n')
+	if n < 0 {
+		if !atEOF {
+			return 0, nil, nil
+		}
+		n = len(data)
+	}
+	s.startToken()
+	if s.siso {
+		for _, c := range data[:n] {
+			if c == 0x0e {
+				s.shiftOffset = 0
+			} else if c == 0x0f {
+				s.shiftOffset = 94 * 94
+			}
+		}
+	}
+	advance := n
+	if n < len(data) {
+		advance += 1
+	}
+	s.offset += advance
+	if n > 0 && data[n-1] == '\r' {
+		n -= 1
+	}
+	return advance, data[:n], nil
+}
+
+// NewJISSplitter creates a splitter for the byte streams encoded in mode.
+func NewJISSplitter(mode ConversionMode) *JISSplitter {
+	return &JISSplitter{
+		siso: mode == ConversionModeSISO,
+		men:  1,
+	}
+}
+
+// ScanJISRecords returns a bufio.SplitFunc that yields fixed-length records
+// of n bytes. It panics if n is not positive.
+func ScanJISRecords(n int) bufio.SplitFunc {
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid record length: %d", n))
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(data) >= n {
+			return n, data[:n], nil
+		}
+		if atEOF && len(data) > 0 {
+			return 0, nil, fmt.Errorf("incomplete record of %d bytes", len(data))
+		}
+		return 0, nil, nil
+	}
+}
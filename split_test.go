@@ -0,0 +1,78 @@
+package jntajis
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanCharacters(t *testing.T) {
+	s := NewJISSplitter(ConversionModeSISO)
+	s.ControlPassthrough = true
+	sc := bufio.NewScanner(bytes.NewReader([]byte{0x30, 0x21, 0x0f, 0x21, 0x21, 0x0a, 0x0e, 0x25, 0x22}))
+	sc.Split(s.ScanCharacters)
+	var tokens [][]byte
+	var mens []int
+	for sc.Scan() {
+		tokens = append(tokens, append([]byte(nil), sc.Bytes()...))
+		mens = append(mens, s.Men())
+	}
+	if assert.NoError(t, sc.Err()) {
+		assert.Equal(t, [][]byte{{0x30, 0x21}, {0x21, 0x21}, {0x0a}, {0x25, 0x22}}, tokens)
+		assert.Equal(t, []int{1, 2, 2, 1}, mens)
+	}
+}
+
+func TestScanCharactersErrors(t *testing.T) {
+	for _, c := range []struct {
+		input    []byte
+		expected string
+	}{
+		{[]byte{0x30, 0x21, 0x30}, "incomplete character at offset 2"},
+		{[]byte{0x30, 0x21, 0x30, 0x0a}, "unexpected byte \\x0a after \\x30 at offset 2"},
+		{[]byte{0x30, 0x21, 0x0a}, "unexpected byte \\x0a at offset 2"},
+	} {
+		sc := bufio.NewScanner(bytes.NewReader(c.input))
+		sc.Split(NewJISSplitter(ConversionModeSISO).ScanCharacters)
+		for sc.Scan() {
+		}
+		assert.EqualError(t, sc.Err(), c.expected)
+	}
+}
+
+func TestScanLines(t *testing.T) {
+	s := NewJISSplitter(ConversionModeSISO)
+	sc := bufio.NewScanner(bytes.NewReader([]byte{0x30, 0x21, 0x0f, 0x21, 0x21, 0x0d, 0x0a, 0x21, 0x21, 0x0a, 0x0e, 0x25, 0x22}))
+	sc.Split(s.ScanLines)
+	var lines []string
+	var mens []int
+	for sc.Scan() {
+		lines = append(lines, string(sc.Bytes()))
+		mens = append(mens, s.Men())
+	}
+	if assert.NoError(t, sc.Err()) {
+		assert.Equal(t, []string{"\x30\x21\x0f\x21\x21", "\x21\x21", "\x0e\x25\x22"}, lines)
+		assert.Equal(t, []int{1, 2, 2}, mens)
+	}
+}
+
+func TestScanJISRecords(t *testing.T) {
+	sc := bufio.NewScanner(bytes.NewReader([]byte{0x30, 0x21, 0x21, 0x21, 0x25, 0x22, 0x25, 0x24}))
+	sc.Split(ScanJISRecords(4))
+	var records [][]byte
+	for sc.Scan() {
+		records = append(records, append([]byte(nil), sc.Bytes()...))
+	}
+	if assert.NoError(t, sc.Err()) {
+		assert.Equal(t, [][]byte{{0x30, 0x21, 0x21, 0x21}, {0x25, 0x22, 0x25, 0x24}}, records)
+	}
+
+	sc = bufio.NewScanner(bytes.NewReader([]byte{0x30, 0x21, 0x21}))
+	sc.Split(ScanJISRecords(4))
+	assert.False(t, sc.Scan())
+	assert.EqualError(t, sc.Err(), "incomplete record of 3 bytes")
+
+	assert.Panics(t, func() { ScanJISRecords(0) })
+}
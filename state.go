@@ -0,0 +1,189 @@
+package jntajis
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	encoderStateMagic   = 'E'
+	decoderStateMagic   = 'D'
+	codecStateVersion   = 1
+	maxEncoderLookahead = 16
+)
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutVarint(buf[:], v)]...)
+}
+
+type stateReader struct {
+	b   []byte
+	err error
+}
+
+func (r *stateReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.err = fmt.Errorf("truncated state")
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *stateReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.b)
+	if n <= 0 {
+		r.err = fmt.Errorf("truncated state")
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func newStateReader(b []byte, magic byte) (*stateReader, error) {
+	if len(b) < 2 || b[0] != magic {
+		return nil, fmt.Errorf("not a state of the codec")
+	}
+	if b[1] != codecStateVersion {
+		return nil, fmt.Errorf("unsupported state version: %d", b[1])
+	}
+	return &stateReader{b: b[2:]}, nil
+}
+
+// MarshalBinary saves the lookahead and the shift state of the encoder. The
+// settings of the encoder are not part of it.
+func (e *JNTAJISIncrementalEncoder) MarshalBinary() ([]byte, error) {
+	b := []byte{encoderStateMagic, codecStateVersion}
+	b = appendUvarint(b, uint64(e.mode))
+	b = appendUvarint(b, uint64(e.shiftState))
+	b = appendUvarint(b, uint64(len(e.lookahead)))
+	for _, r := range e.lookahead {
+		b = appendVarint(b, int64(r))
+	}
+	return b, nil
+}
+
+// UnmarshalBinary restores the state saved by MarshalBinary. The encoder must
+// have been created for the same mode.
+func (e *JNTAJISIncrementalEncoder) UnmarshalBinary(b []byte) error {
+	r, err := newStateReader(b, encoderStateMagic)
+	if err != nil {
+		return err
+	}
+	mode := ConversionMode(r.uvarint())
+	shiftState := int(r.uvarint())
+	n := int(r.uvarint())
+	if r.err == nil && n > maxEncoderLookahead {
+		return fmt.Errorf("lookahead too long: %d", n)
+	}
+	lookahead := make([]rune, 0, 2)
+	for i := 0; i < n; i++ {
+		lookahead = append(lookahead, rune(r.varint()))
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if mode != e.mode {
+		return fmt.Errorf("state was saved by an encoder for %s", mode)
+	}
+	if shiftState != 0 && shiftState != 1 {
+		return fmt.Errorf("invalid shift state: %d", shiftState)
+	}
+	// the state of the pair matcher depends on the table, so it is rebuilt
+	// rather than saved
+	state := 0
+	if n > 0 {
+		last := lookahead[n-1]
+		if state, _ = smRuneToJISMapping(0, last); state <= 0 {
+			return fmt.Errorf("lookahead %U does not start a combining pair", last)
+		}
+	}
+	e.shiftState, e.state, e.lookahead = shiftState, state, lookahead
+	return nil
+}
+
+// Reset discards the lookahead and the shift state without emitting them.
+func (e *JNTAJISIncrementalEncoder) Reset() {
+	e.lookahead = e.lookahead[:0]
+	e.shiftState = 0
+	e.state = 0
+}
+
+// Clone returns a copy of the encoder that shares the settings but not the
+// state.
+func (e *JNTAJISIncrementalEncoder) Clone() *JNTAJISIncrementalEncoder {
+	c := new(JNTAJISIncrementalEncoder)
+	*c = *e
+	c.lookahead = append(make([]rune, 0, 2), e.lookahead...)
+	if c.mode == ConversionModeSISO {
+		c.putJIS = c.putJISSISO
+	}
+	return c
+}
+
+// MarshalBinary saves the shift state and the incomplete character of the
+// decoder. The settings of the decoder are not part of it.
+func (d *JNTAJISDecoder) MarshalBinary() ([]byte, error) {
+	b := []byte{decoderStateMagic, codecStateVersion}
+	var siso uint64
+	if d.siso {
+		siso = 1
+	}
+	b = appendUvarint(b, siso)
+	b = appendUvarint(b, uint64(d.shiftOffset))
+	b = appendUvarint(b, uint64(d.upper))
+	return b, nil
+}
+
+// UnmarshalBinary restores the state saved by MarshalBinary. The decoder must
+// have been created for the same mode.
+func (d *JNTAJISDecoder) UnmarshalBinary(b []byte) error {
+	r, err := newStateReader(b, decoderStateMagic)
+	if err != nil {
+		return err
+	}
+	siso := r.uvarint() != 0
+	shiftOffset := int(r.uvarint())
+	upper := int(r.uvarint())
+	if r.err != nil {
+		return r.err
+	}
+	if siso != d.siso {
+		return fmt.Errorf("state was saved by a decoder for another mode")
+	}
+	if shiftOffset != 0 && shiftOffset != 94*94 {
+		return fmt.Errorf("invalid shift state: %d", shiftOffset)
+	}
+	if upper != 0 && (upper < 0x21 || upper > 0x7e) {
+		return fmt.Errorf("invalid incomplete character: \\x%02x", upper)
+	}
+	d.shiftOffset, d.upper = shiftOffset, upper
+	return nil
+}
+
+// Reset discards the shift state and the incomplete character.
+func (d *JNTAJISDecoder) Reset() {
+	d.shiftOffset = 0
+	d.upper = 0
+}
+
+// Clone returns a copy of the decoder that shares the settings but not the
+// state.
+func (d *JNTAJISDecoder) Clone() *JNTAJISDecoder {
+	c := new(JNTAJISDecoder)
+	*c = *d
+	return c
+}
@@ -0,0 +1,113 @@
+package jntajis
+
+import (
+	"encoding"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*JNTAJISIncrementalEncoder)(nil)
+	_ encoding.BinaryUnmarshaler = (*JNTAJISIncrementalEncoder)(nil)
+	_ encoding.BinaryMarshaler   = (*JNTAJISDecoder)(nil)
+	_ encoding.BinaryUnmarshaler = (*JNTAJISDecoder)(nil)
+)
+
+func TestIncrementalEncoderStateRestore(t *testing.T) {
+	input1, input2 := "山\U00020089か", "゚亜"
+	enc := NewJNTAJISIncrementalEncoder(ConversionModeSISO, InvalidJISCode)
+	expected, err := enc.Encode(nil, input1+input2)
+	if assert.NoError(t, err) {
+		expected, err = enc.Flush(expected)
+	}
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	enc = NewJNTAJISIncrementalEncoder(ConversionModeSISO, InvalidJISCode)
+	b, err := enc.Encode(nil, input1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	state, err := enc.MarshalBinary()
+	if !assert.NoError(t, err) {
+		return
+	}
+	enc = NewJNTAJISIncrementalEncoder(ConversionModeSISO, InvalidJISCode)
+	if !assert.NoError(t, enc.UnmarshalBinary(state)) {
+		return
+	}
+	clone := enc.Clone()
+	b, err = enc.Encode(b, input2)
+	if assert.NoError(t, err) {
+		b, err = enc.Flush(b)
+	}
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, b)
+	}
+
+	enc.Reset()
+	b, err = enc.Encode(nil, "\U00020089")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x0f, 0x21, 0x21}, b)
+	}
+
+	// the clone keeps the restored state and its own shift state
+	b, err = clone.Encode(nil, input2)
+	if assert.NoError(t, err) {
+		b, err = clone.Flush(b)
+	}
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected[len(expected)-len(b):], b)
+	}
+
+	other := NewJNTAJISIncrementalEncoder(ConversionModeMen1, InvalidJISCode)
+	assert.EqualError(t, other.UnmarshalBinary(state), "state was saved by an encoder for ConversionModeSISO")
+	assert.EqualError(t, other.UnmarshalBinary(state[:3]), "truncated state")
+	assert.EqualError(t, other.UnmarshalBinary([]byte{'D', 1}), "not a state of the codec")
+	state = appendVarint([]byte{'E', 1, byte(ConversionModeMen1), 0, 1}, int64('亜'))
+	assert.EqualError(t, other.UnmarshalBinary(state), "lookahead U+4E9C does not start a combining pair")
+	state = appendVarint([]byte{'E', 1, byte(ConversionModeMen1), 0, 1}, int64('か'))
+	if assert.NoError(t, other.UnmarshalBinary(state)) {
+		b, err = other.Encode(nil, "゚")
+		if assert.NoError(t, err) {
+			assert.Equal(t, []byte{0x24, 0x77}, b)
+		}
+	}
+}
+
+func TestDecoderStateRestore(t *testing.T) {
+	dec := NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+	b, err := dec.Decode(nil, []byte{0x30, 0x21, 0x0f, 0x21})
+	if !assert.NoError(t, err) {
+		return
+	}
+	state, err := dec.MarshalBinary()
+	if !assert.NoError(t, err) {
+		return
+	}
+	dec = NewJNTAJISDecoder(ConversionModeSISO, InvalidRune)
+	if !assert.NoError(t, dec.UnmarshalBinary(state)) {
+		return
+	}
+	clone := dec.Clone()
+	b, err = dec.Decode(b, []byte{0x21, 0x21, 0x21})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "亜\U00020089\U00020089", string(b))
+	}
+	b, err = clone.Decode(nil, []byte{0x21})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "\U00020089", string(b))
+	}
+
+	dec.Reset()
+	b, err = dec.Decode(nil, []byte{0x30, 0x21})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "亜", string(b))
+	}
+
+	other := NewJNTAJISDecoder(ConversionModeMen1, InvalidRune)
+	assert.EqualError(t, other.UnmarshalBinary(state), "state was saved by a decoder for another mode")
+	assert.EqualError(t, dec.UnmarshalBinary([]byte{'D', 1, 1, 0, 0x20}), "invalid incomplete character: \\x20")
+}
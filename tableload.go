@@ -0,0 +1,146 @@
+package jntajis
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"sync"
+)
+
+// tableBlob is generated by gen.go along with table.go. It starts with the
+// magic "JNTA", a version byte and the CRC-32 (IEEE) of the rest, which holds
+// the mappings followed by the rune ranges, all numbers being uvarints.
+//
+//go:embed table.bin
+var tableBlob []byte
+
+const tableBlobVersion = 1
+
+var (
+	tableOnce             sync.Once
+	txMappingsTable       [2 * 94 * 94]shrinkingTransliterationMapping
+	revTrieTable          *revTrie
+	runePairStartersTable []runePairStarter
+)
+
+// txMappings returns the NTA table indexed by packed men-ku-ten code.
+func txMappings() *[2 * 94 * 94]shrinkingTransliterationMapping {
+	tableOnce.Do(loadTables)
+	return &txMappingsTable
+}
+
+type tableReader struct {
+	b   []byte
+	err error
+}
+
+func (r *tableReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.err = fmt.Errorf("truncated table")
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *tableReader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.b) == 0 {
+		r.err = fmt.Errorf("truncated table")
+		return 0
+	}
+	v := r.b[0]
+	r.b = r.b[1:]
+	return v
+}
+
+// rune reads a rune stored as r+1, or 0 for InvalidRune.
+func (r *tableReader) rune() rune {
+	v := r.uvarint()
+	if v == 0 {
+		return InvalidRune
+	}
+	return rune(v - 1)
+}
+
+func decodeTables(blob []byte, mappings *[2 * 94 * 94]shrinkingTransliterationMapping) ([]runeRangeToJISMapping, error) {
+	if len(blob) < 9 || string(blob[:4]) != "JNTA" {
+		return nil, fmt.Errorf("not a table")
+	}
+	if blob[4] != tableBlobVersion {
+		return nil, fmt.Errorf("unsupported table version: %d", blob[4])
+	}
+	if crc32.ChecksumIEEE(blob[9:]) != binary.LittleEndian.Uint32(blob[5:9]) {
+		return nil, fmt.Errorf("checksum mismatch")
+	}
+	r := &tableReader{b: blob[9:]}
+	n := int(r.uvarint())
+	if n > len(mappings) {
+		return nil, fmt.Errorf("too many mappings: %d", n)
+	}
+	for i := 0; i < n && r.err == nil; i++ {
+		m := &mappings[i]
+		m.jis = uint32(r.uvarint())
+		m.rs = [2]rune{r.rune(), r.rune()}
+		m.srs = [2]rune{r.rune(), r.rune()}
+		m.class = JISCharacterClass(r.byte())
+		m.txLen = r.byte()
+		for j := range m.txJIS {
+			m.txJIS[j] = uint32(r.uvarint())
+		}
+		for j := range m.txRunes {
+			m.txRunes[j] = rune(r.uvarint())
+		}
+	}
+	n = int(r.uvarint())
+	if r.err != nil {
+		return nil, r.err
+	}
+	ranges := make([]runeRangeToJISMapping, n)
+	var jis []uint32
+	for i := range ranges {
+		rm := &ranges[i]
+		rm.start = rune(r.uvarint())
+		rm.end = rune(r.uvarint())
+		l := int(r.uvarint())
+		if r.err != nil {
+			return nil, r.err
+		}
+		if l > len(r.b) {
+			return nil, fmt.Errorf("truncated table")
+		}
+		if cap(jis)-len(jis) < l {
+			jis = make([]uint32, 0, l+len(r.b))
+		}
+		start := len(jis)
+		for j := 0; j < l; j++ {
+			// stored as c+1, or 0 for InvalidJISCode
+			jis = append(jis, uint32(r.uvarint())-1)
+		}
+		rm.jis = jis[start:len(jis):len(jis)]
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.b) != 0 {
+		return nil, fmt.Errorf("%d extra bytes at the end of the table", len(r.b))
+	}
+	return ranges, nil
+}
+
+func loadTables() {
+	ranges, err := decodeTables(tableBlob, &txMappingsTable)
+	if err != nil {
+		panic(fmt.Sprintf("corrupted table.bin: %s", err))
+	}
+	// the rune ranges are only needed to build the trie
+	revTrieTable = buildRevTrie(ranges)
+	runePairStartersTable = buildRunePairStarters(&txMappingsTable)
+}
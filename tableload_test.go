@@ -0,0 +1,106 @@
+package jntajis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tablesSHA256 is the digest of dumpTables over the tables that table.go
+// held as Go literals before table.bin replaced them. Those were generated
+// before gen.go read the similar glyph forms (srs) from the notes, so the
+// digest must be updated along with table.bin once it is regenerated from
+// the sheet; otherwise it changes only when the NTA table does.
+const tablesSHA256 = "f7cde6f3a79e303e3b556907892df96bdd0e15222739a765119b92098856277b"
+
+// dumpTables writes the tables in a canonical text form: every cell, every
+// rune that has a reverse mapping and every combining pair that the matcher
+// recognizes.
+func dumpTables(w io.Writer, mappings *[2 * 94 * 94]shrinkingTransliterationMapping, lookup func(rune) (uint32, bool), sm func(int, rune) (int, uint32)) {
+	seen := make(map[rune]bool)
+	var marks []rune
+	for i := range mappings {
+		m := &mappings[i]
+		fmt.Fprintf(w, "%d %d %v %v %d %d %v %v\n", i, m.jis, m.rs, m.srs, m.class, m.txLen, m.txJIS, m.txRunes)
+		if m.rs[1] != InvalidRune && !seen[m.rs[1]] {
+			seen[m.rs[1]] = true
+			marks = append(marks, m.rs[1])
+		}
+	}
+	sort.Slice(marks, func(i, j int) bool { return marks[i] < marks[j] })
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if jis, ok := lookup(r); ok {
+			fmt.Fprintf(w, "%U %d\n", r, jis)
+		}
+		if state, _ := sm(0, r); state > 0 {
+			for _, mark := range marks {
+				if s, jis := sm(state, mark); s == -1 {
+					fmt.Fprintf(w, "%U %U %d\n", r, mark, jis)
+				}
+			}
+		}
+	}
+}
+
+func TestDecodeTables(t *testing.T) {
+	var m [2 * 94 * 94]shrinkingTransliterationMapping
+	ranges, err := decodeTables(tableBlob, &m)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, *txMappings(), m)
+	assert.Equal(t, revTrieTable, buildRevTrie(ranges))
+
+	a := m[MenKuTen{1, 16, 1}.JIS()]
+	assert.Equal(t, [2]rune{'亜', InvalidRune}, a.rs)
+	assert.Equal(t, KanjiLevel1, a.class)
+	jis, ok := lookupRevTable('亜')
+	if assert.True(t, ok) {
+		assert.Equal(t, MenKuTen{1, 16, 1}.JIS(), jis)
+	}
+}
+
+func TestTablesGolden(t *testing.T) {
+	h := sha256.New()
+	dumpTables(h, txMappings(), lookupRevTable, smRuneToJISMapping)
+	assert.Equal(t, tablesSHA256, hex.EncodeToString(h.Sum(nil)))
+
+	for _, c := range []struct {
+		mkt   MenKuTen
+		rs    [2]rune
+		class JISCharacterClass
+	}{
+		{MenKuTen{1, 1, 1}, [2]rune{'\u3000', InvalidRune}, JISX0208NonKanji},
+		{MenKuTen{1, 4, 87}, [2]rune{'か', '\u309a'}, JISX0213NonKanji},
+		{MenKuTen{1, 16, 1}, [2]rune{'亜', InvalidRune}, KanjiLevel1},
+		{MenKuTen{1, 48, 1}, [2]rune{'弌', InvalidRune}, KanjiLevel2},
+		{MenKuTen{1, 94, 94}, [2]rune{'繫', InvalidRune}, KanjiLevel3},
+		{MenKuTen{2, 1, 1}, [2]rune{'\U00020089', InvalidRune}, KanjiLevel4},
+	} {
+		m := &txMappings()[c.mkt.JIS()]
+		assert.Equal(t, c.rs, m.rs, c.mkt.String())
+		assert.Equal(t, c.class, m.class, c.mkt.String())
+	}
+}
+
+func TestDecodeTablesCorrupted(t *testing.T) {
+	var m [2 * 94 * 94]shrinkingTransliterationMapping
+	blob := append([]byte(nil), tableBlob...)
+	blob[len(blob)/2] ^= 0xff
+	_, err := decodeTables(blob, &m)
+	assert.EqualError(t, err, "checksum mismatch")
+
+	blob = append([]byte(nil), tableBlob...)
+	blob[4] = 2
+	_, err = decodeTables(blob, &m)
+	assert.EqualError(t, err, "unsupported table version: 2")
+
+	_, err = decodeTables([]byte("JNTB"), &m)
+	assert.EqualError(t, err, "not a table")
+}
@@ -0,0 +1,94 @@
+package jntajis
+
+import "fmt"
+
+// JISToken is a character read from JIS bytes.
+type JISToken struct {
+	// zero for a control character
+	MenKuTen MenKuTen
+	// the Unicode characters of the cell, or the control character itself;
+	// nil for a reserved cell
+	Runes []rune
+	// byte offset in the input
+	Offset int
+	// Reserved for a reserved cell or a control character
+	Class JISCharacterClass
+}
+
+// JISTokenizer reads JIS bytes character by character without decoding them.
+type JISTokenizer struct {
+	d     JNTAJISDecoder
+	in    []byte
+	i     int
+	rs    [2]rune
+	token JISToken
+	err   error
+}
+
+// SetControlPassthrough makes the tokenizer yield the control characters
+// as JNTAJISDecoder does with ControlPassthrough.
+func (t *JISTokenizer) SetControlPassthrough(v bool) {
+	t.d.ControlPassthrough = v
+}
+
+// Next advances the tokenizer to the next character, and returns false at
+// the end of the input or on error.
+func (t *JISTokenizer) Next() bool {
+	if t.err != nil {
+		return false
+	}
+	for t.i < len(t.in) {
+		kind, v, o, ni, err := t.d.nextUnit(t.in, t.i)
+		if err != nil {
+			t.err = err
+			return false
+		}
+		t.i = ni
+		switch kind {
+		case unitJIS:
+			m := &txMappings()[v]
+			t.token = JISToken{
+				MenKuTen: MenKuTenFromJIS(uint32(v)),
+				Offset:   o,
+				Class:    m.class,
+			}
+			if m.class != Reserved {
+				t.rs = m.rs
+				if m.rs[1] == InvalidRune {
+					t.token.Runes = t.rs[:1]
+				} else {
+					t.token.Runes = t.rs[:]
+				}
+			}
+			return true
+		case unitControl:
+			t.rs[0] = rune(v)
+			t.token = JISToken{Runes: t.rs[:1], Offset: o, Class: Reserved}
+			return true
+		}
+		if t.d.upper > 0 {
+			t.err = fmt.Errorf("incomplete character at offset %d", o)
+			return false
+		}
+	}
+	return false
+}
+
+// Token returns the current character. Runes stays valid until the next
+// call to Next.
+func (t *JISTokenizer) Token() JISToken {
+	return t.token
+}
+
+// Err returns the error that stopped the tokenizer, if any.
+func (t *JISTokenizer) Err() error {
+	return t.err
+}
+
+// NewJISTokenizer creates a tokenizer over in encoded in mode.
+func NewJISTokenizer(mode ConversionMode, in []byte) *JISTokenizer {
+	return &JISTokenizer{
+		d:  JNTAJISDecoder{siso: mode == ConversionModeSISO},
+		in: in,
+	}
+}
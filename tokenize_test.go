@@ -0,0 +1,44 @@
+package jntajis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJISTokenizer(t *testing.T) {
+	tk := NewJISTokenizer(ConversionModeSISO, []byte{0x30, 0x21, 0x0f, 0x21, 0x21, 0x0e, 0x24, 0x77, 0x22, 0x31, 0x0a})
+	tk.SetControlPassthrough(true)
+	var tokens []JISToken
+	for tk.Next() {
+		tok := tk.Token()
+		tok.Runes = append([]rune(nil), tok.Runes...)
+		tokens = append(tokens, tok)
+	}
+	if assert.NoError(t, tk.Err()) {
+		assert.Equal(t, []JISToken{
+			{MenKuTen: MenKuTen{1, 16, 1}, Runes: []rune{'亜'}, Offset: 0, Class: KanjiLevel1},
+			{MenKuTen: MenKuTen{2, 1, 1}, Runes: []rune{'\U00020089'}, Offset: 3, Class: KanjiLevel4},
+			{MenKuTen: MenKuTen{1, 4, 87}, Runes: []rune{'か', '゚'}, Offset: 6, Class: JISX0213NonKanji},
+			{MenKuTen: MenKuTen{1, 2, 17}, Offset: 8, Class: Reserved},
+			{Runes: []rune{'\n'}, Offset: 10, Class: Reserved},
+		}, tokens)
+	}
+}
+
+func TestJISTokenizerErrors(t *testing.T) {
+	for _, c := range []struct {
+		input    []byte
+		expected string
+	}{
+		{[]byte{0x30, 0x21, 0x30}, "incomplete character at offset 2"},
+		{[]byte{0x30, 0x21, 0x0a}, "unexpected byte \\x0a at offset 2"},
+		{[]byte{0x30, 0x0a}, "unexpected byte \\x0a after \\x30 at offset 0"},
+	} {
+		tk := NewJISTokenizer(ConversionModeSISO, c.input)
+		for tk.Next() {
+		}
+		assert.EqualError(t, tk.Err(), c.expected)
+		assert.False(t, tk.Next())
+	}
+}
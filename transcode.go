@@ -0,0 +1,120 @@
+package jntajis
+
+import "fmt"
+
+// CellRules maps JIS X 0213 cells to sequences of JIS X 0208 characters in
+// place of the transliteration in the NTA table. A CellRules must not be
+// modified while a transcoder is using it.
+type CellRules struct {
+	m map[uint32][]uint32
+}
+
+func NewCellRules() *CellRules {
+	return &CellRules{m: make(map[uint32][]uint32)}
+}
+
+// Set maps the cell jis to the given sequence of JIS X 0208 characters.
+func (c *CellRules) Set(jis uint32, to ...uint32) error {
+	if int(jis) >= len(txMappings()) {
+		return fmt.Errorf("invalid JIS code: %d", jis)
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("empty rule for %s", MenKuTenFromJIS(jis))
+	}
+	for _, t := range to {
+		if !isJISX0208(t) {
+			return fmt.Errorf("rule for %s contains a character not in JISX0208: %s", MenKuTenFromJIS(jis), MenKuTenFromJIS(t))
+		}
+	}
+	c.m[jis] = append([]uint32(nil), to...)
+	return nil
+}
+
+// JISX0213To0208Transcoder converts JIS X 0213 bytes to JIS X 0208 bytes
+// cell by cell without going through Unicode. A cell is looked up in Rules,
+// the gaiji registry and then in the NTA table in this order.
+type JISX0213To0208Transcoder struct {
+	Rules *CellRules
+	// Gaiji supplies the transliteration of the registered reserved cells.
+	Gaiji *GaijiRegistry
+	// Replacement is the JIS X 0208 character put for inconvertible cells,
+	// or InvalidJISCode to fail. Transcode fails on an inconvertible cell as
+	// well if Replacement is not in JIS X 0208.
+	Replacement uint32
+	// ControlPassthrough makes the control characters copied as they are.
+	ControlPassthrough bool
+	d                  JNTAJISDecoder
+}
+
+func (t *JISX0213To0208Transcoder) putCell(b []byte, jis uint32, o int) ([]byte, error) {
+	if t.Rules != nil {
+		if to, ok := t.Rules.m[jis]; ok {
+			for _, c := range to {
+				b, _ = putJISMen1(b, c)
+			}
+			return b, nil
+		}
+	}
+	if isJISX0208(jis) {
+		b, _ = putJISMen1(b, jis)
+		return b, nil
+	}
+	m := &txMappings()[jis]
+	if m.class == Reserved {
+		if t.Gaiji != nil {
+			if ge, ok := t.Gaiji.byJIS[jis]; ok && len(ge.translit) > 0 {
+				for _, c := range ge.translit {
+					b, _ = putJISMen1(b, c)
+				}
+				return b, nil
+			}
+		}
+	} else if m.txLen > 0 {
+		for _, c := range m.txJIS[:m.txLen] {
+			b, _ = putJISMen1(b, c)
+		}
+		return b, nil
+	}
+	if t.Replacement == InvalidJISCode {
+		return b, fmt.Errorf("inconvertible character %s found at offset %d", MenKuTenFromJIS(jis), o)
+	}
+	if !isJISX0208(t.Replacement) {
+		return b, fmt.Errorf("replacement character %s is not in JISX0208", MenKuTenFromJIS(t.Replacement))
+	}
+	b, _ = putJISMen1(b, t.Replacement)
+	return b, nil
+}
+
+// Transcode appends the JIS X 0208 bytes for in to b. Like
+// JNTAJISDecoder.Decode, it keeps the shift state and an incomplete
+// character across calls.
+func (t *JISX0213To0208Transcoder) Transcode(b []byte, in []byte) ([]byte, error) {
+	t.d.ControlPassthrough = t.ControlPassthrough
+	i := 0
+	for i < len(in) {
+		kind, v, o, ni, err := t.d.nextUnit(in, i)
+		if err != nil {
+			return nil, err
+		}
+		i = ni
+		switch kind {
+		case unitJIS:
+			b, err = t.putCell(b, uint32(v), o)
+			if err != nil {
+				return b, err
+			}
+		case unitControl:
+			b = append(b, byte(v))
+		}
+	}
+	return b, nil
+}
+
+// NewJISX0213To0208Transcoder creates a transcoder for the bytes encoded in
+// mode, which is either ConversionModeSISO or ConversionModeMen1.
+func NewJISX0213To0208Transcoder(mode ConversionMode, replacement uint32) *JISX0213To0208Transcoder {
+	return &JISX0213To0208Transcoder{
+		Replacement: replacement,
+		d:           JNTAJISDecoder{siso: mode == ConversionModeSISO},
+	}
+}
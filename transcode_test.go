@@ -0,0 +1,60 @@
+package jntajis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJISX0213To0208Transcoder(t *testing.T) {
+	tc := NewJISX0213To0208Transcoder(ConversionModeSISO, InvalidJISCode)
+	b, err := tc.Transcode(nil, []byte{0x30, 0x21, 0x7e})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x30, 0x21}, b)
+	}
+	b, err = tc.Transcode(b, []byte{0x7e})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x30, 0x21, 0x37, 0x52}, b)
+	}
+	_, err = tc.Transcode(nil, []byte{0x22, 0x31})
+	assert.EqualError(t, err, "inconvertible character 1-2-17 found at offset 0")
+
+	r := NewCellRules()
+	assert.NoError(t, r.Set(MenKuTen{1, 94, 94}.JIS(), MenKuTen{1, 16, 1}.JIS(), MenKuTen{1, 16, 1}.JIS()))
+	assert.EqualError(t, r.Set(MenKuTen{1, 94, 93}.JIS(), MenKuTen{1, 94, 94}.JIS()), "rule for 1-94-93 contains a character not in JISX0208: 1-94-94")
+	g := NewGaijiRegistry()
+	assert.NoError(t, g.Register(0xe000, MenKuTen{1, 2, 17}.JIS(), MenKuTen{1, 2, 14}.JIS()))
+	tc = NewJISX0213To0208Transcoder(ConversionModeMen1, MenKuTen{1, 2, 2}.JIS())
+	tc.Rules = r
+	tc.Gaiji = g
+	tc.ControlPassthrough = true
+	b, err = tc.Transcode(nil, []byte{0x7e, 0x7e, 0x22, 0x31, 0x22, 0x32, 0x0a})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x30, 0x21, 0x30, 0x21, 0x22, 0x2e, 0x22, 0x22, 0x0a}, b)
+	}
+
+	tc = NewJISX0213To0208Transcoder(ConversionModeMen1, MenKuTen{1, 94, 94}.JIS())
+	b, err = tc.Transcode(nil, []byte{0x30, 0x21, 0x22, 0x31})
+	assert.Equal(t, []byte{0x30, 0x21}, b)
+	assert.EqualError(t, err, "replacement character 1-94-94 is not in JISX0208")
+}
+
+func TestJISX0213To0208TranscoderAgreesWithEncoder(t *testing.T) {
+	for _, input := range []string{"ジャンクロードヴァンダム", "ゔゕゖ㉑繫", "かか゚\U00020089"} {
+		enc := NewJNTAJISIncrementalEncoder(ConversionModeSISO, InvalidJISCode)
+		b, err := enc.Encode(nil, input)
+		if assert.NoError(t, err) {
+			b, err = enc.Flush(b)
+		}
+		if !assert.NoError(t, err) {
+			continue
+		}
+		expected, eerr := NewJNTAJISEncoder(ConversionModeTranslit, InvalidJISCode).EncodeAsJISX0213Men1(input)
+		result, terr := NewJISX0213To0208Transcoder(ConversionModeSISO, InvalidJISCode).Transcode(nil, b)
+		if eerr != nil {
+			assert.Error(t, terr, input)
+		} else if assert.NoError(t, terr, input) {
+			assert.Equal(t, expected, result, input)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package jntajis
+
+import "unicode/utf8"
+
+func appendRunes(b []byte, rs []rune) []byte {
+	for _, r := range rs {
+		if r == InvalidRune {
+			break
+		}
+		b = appendRune(b, r)
+	}
+	return b
+}
+
+// appendTransliterated appends the transliterated form of s to b, recording
+// the correspondence in a unless it is nil.
+func appendTransliterated(b []byte, s string, a *Alignment) []byte {
+	outRunes := 0
+	forEachSegment(s, func(seg segment) error {
+		start := len(b)
+		jis, ok := segmentJIS(&seg)
+		if ok {
+			m := &txMappings()[jis]
+			if isJISX0208(jis) {
+				b = appendRunes(b, m.rs[:])
+			} else if m.txLen > 0 {
+				b = appendRunes(b, m.txRunes[:m.txLen])
+			} else {
+				ok = false
+			}
+		}
+		if !ok {
+			b = append(b, s[seg.offset:seg.offset+seg.size]...)
+		}
+		if a != nil {
+			n := utf8.RuneCount(b[start:])
+			a.add(&seg, start, len(b), outRunes, outRunes+n)
+			outRunes += n
+		}
+		return nil
+	})
+	return b
+}
+
+// Transliterate returns s with every character outside JIS X 0208 replaced by
+// its transliterated form given in the NTA table, and similar glyphs folded
+// into their primary form. Characters that are not in JIS X 0213 or that have
+// no transliterated form are left as is.
+func Transliterate(s string) string {
+	return string(appendTransliterated(make([]byte, 0, len(s)), s, nil))
+}
+
+// TransliterateWithAlignment is like Transliterate but also returns the
+// alignment between the input and the output.
+func TransliterateWithAlignment(s string) (string, *Alignment) {
+	a := new(Alignment)
+	return string(appendTransliterated(make([]byte, 0, len(s)), s, a)), a
+}
@@ -0,0 +1,42 @@
+package jntajis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransliterate(t *testing.T) {
+	cases := []struct {
+		expected string
+		input    string
+	}{
+		{
+			expected: "ジャンクロードヴァンダム",
+			input:    "ジャンクロードヴァンダム",
+		},
+		{
+			expected: "崎繋",
+			input:    "﨑繫",
+		},
+		{
+			expected: "２１",
+			input:    "㉑",
+		},
+		{
+			expected: "ヴヵヶ",
+			input:    "ゔゕゖ",
+		},
+		{
+			expected: "Aか゚\U00020089",
+			input:    "Aか゚\U00020089",
+		},
+	}
+
+	for i, case_ := range cases {
+		t.Run(fmt.Sprintf("%d: %s", i, case_.input), func(t *testing.T) {
+			assert.Equal(t, case_.expected, Transliterate(case_.input))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package jntajis
+
+import "errors"
+
+var errNotRepresentable = errors.New("not representable")
+
+// Problem describes a character that cannot be represented in a conversion
+// mode.
+type Problem struct {
+	// the offending character; a combining sequence for a pair
+	Text string
+	// byte offset in the input
+	Offset int
+	// rune index in the input
+	Index int
+	// packed men-ku-ten code, or InvalidJISCode if the character is not in
+	// JIS X 0213 at all
+	JIS uint32
+	// Reserved if the character is not in JIS X 0213 at all
+	Class JISCharacterClass
+	// whether the NTA table gives a transliterated form of the character
+	HasTranslit bool
+}
+
+// isRepresentable reports whether the cell can be put in the mode without
+// falling back to the replacement.
+func isRepresentable(jis uint32, mode ConversionMode) bool {
+	if int(jis) >= len(txMappings()) {
+		return false
+	}
+	m := &txMappings()[jis]
+	if m.class == Reserved {
+		return false
+	}
+	switch mode {
+	case ConversionModeSISO:
+		return true
+	case ConversionModeMen1:
+		return jis < 94*94
+	case ConversionModeJISX0208:
+		return isJISX0208(jis)
+	case ConversionModeTranslit:
+		return isJISX0208(jis) || m.txLen > 0
+	default:
+		return false
+	}
+}
+
+func segmentJIS(seg *segment) (uint32, bool) {
+	if seg.pair {
+		return seg.jis, true
+	}
+	return lookupRevTable(seg.r)
+}
+
+// Validate returns every character of s that cannot be represented in the
+// mode, using the NTA table only.
+func Validate(s string, mode ConversionMode) []Problem {
+	var problems []Problem
+	forEachSegment(s, func(seg segment) error {
+		jis, ok := segmentJIS(&seg)
+		if ok && isRepresentable(jis, mode) {
+			return nil
+		}
+		p := Problem{
+			Text:   s[seg.offset : seg.offset+seg.size],
+			Offset: seg.offset,
+			Index:  seg.index,
+			JIS:    InvalidJISCode,
+			Class:  Reserved,
+		}
+		if ok {
+			m := &txMappings()[jis]
+			p.JIS = jis
+			p.Class = m.class
+			p.HasTranslit = m.txLen > 0
+		}
+		problems = append(problems, p)
+		return nil
+	})
+	return problems
+}
+
+// IsRepresentable reports whether s can be converted in the mode without any
+// replacement.
+func IsRepresentable(s string, mode ConversionMode) bool {
+	return forEachSegment(s, func(seg segment) error {
+		jis, ok := segmentJIS(&seg)
+		if ok && isRepresentable(jis, mode) {
+			return nil
+		}
+		return errNotRepresentable
+	}) == nil
+}
@@ -0,0 +1,59 @@
+package jntajis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	input := "ジ\U00020089繫かか゚㉑A"
+	cases := []struct {
+		expected []Problem
+		mode     ConversionMode
+	}{
+		{
+			expected: []Problem{
+				{Text: "A", Offset: 22, Index: 7, JIS: InvalidJISCode, Class: Reserved},
+			},
+			mode: ConversionModeSISO,
+		},
+		{
+			expected: []Problem{
+				{Text: "\U00020089", Offset: 3, Index: 1, JIS: 94 * 94, Class: KanjiLevel4},
+				{Text: "A", Offset: 22, Index: 7, JIS: InvalidJISCode, Class: Reserved},
+			},
+			mode: ConversionModeMen1,
+		},
+		{
+			expected: []Problem{
+				{Text: "\U00020089", Offset: 3, Index: 1, JIS: 94 * 94, Class: KanjiLevel4},
+				{Text: "繫", Offset: 7, Index: 2, JIS: 93*94 + 93, Class: KanjiLevel3, HasTranslit: true},
+				{Text: "か゚", Offset: 13, Index: 4, JIS: 3*94 + 86, Class: JISX0213NonKanji},
+				{Text: "㉑", Offset: 19, Index: 6, JIS: 7*94 + 32, Class: JISX0213NonKanji, HasTranslit: true},
+				{Text: "A", Offset: 22, Index: 7, JIS: InvalidJISCode, Class: Reserved},
+			},
+			mode: ConversionModeJISX0208,
+		},
+		{
+			expected: []Problem{
+				{Text: "\U00020089", Offset: 3, Index: 1, JIS: 94 * 94, Class: KanjiLevel4},
+				{Text: "か゚", Offset: 13, Index: 4, JIS: 3*94 + 86, Class: JISX0213NonKanji},
+				{Text: "A", Offset: 22, Index: 7, JIS: InvalidJISCode, Class: Reserved},
+			},
+			mode: ConversionModeTranslit,
+		},
+	}
+
+	for i, case_ := range cases {
+		t.Run(fmt.Sprintf("%d: %s", i, case_.mode), func(t *testing.T) {
+			assert.Equal(t, case_.expected, Validate(input, case_.mode))
+			assert.False(t, IsRepresentable(input, case_.mode))
+		})
+	}
+
+	assert.Nil(t, Validate("ジャンクロードヴァンダム", ConversionModeJISX0208))
+	assert.True(t, IsRepresentable("ジャンクロードヴァンダム", ConversionModeJISX0208))
+	assert.True(t, IsRepresentable("", ConversionModeJISX0208))
+}